@@ -1,7 +1,14 @@
 package cmd
 
 import (
+	"fmt"
+	"regexp"
+	"time"
+
+	jiralib "github.com/andygrunwald/go-jira"
 	"github.com/pkg/errors"
+
+	"github.com/ystia/zenhub-jira-sync/pkg/logging"
 )
 
 // Config represents a ZenHub To Jira Configuration
@@ -14,16 +21,149 @@ type Config struct {
 	GithubAPIToken        string             `mapstructure:"github_api_token"`
 	IssueLabelToType      *IssueLabelToType  `mapstructure:"issues_label_to_type"`
 	DefaultJiraComponents []string           `mapstructure:"default_jira_components"`
+	StateDir              string             `mapstructure:"state_dir"`
+	SyncInterval          time.Duration      `mapstructure:"sync_interval"`
+	Webhook               Webhook            `mapstructure:"webhook"`
+	Concurrency           int                `mapstructure:"concurrency"`
+	UserMapping           UserMapping        `mapstructure:"user_mapping"`
+	// CustomFields overrides names of Jira fields used by the synchronization, values are either a field name or ID
+	// such as customfield_10010 indexed by field key (github_id, epic_link, ...)
+	CustomFields map[string]string `mapstructure:"custom_fields"`
+	// EstimableIssueTypes overrides the discovery of issue types supporting estimation
+	EstimableIssueTypes []string      `mapstructure:"estimable_issue_types"`
+	OrphanIssues        *OrphanIssues `mapstructure:"orphan_issues"`
+	NativeLabels        *NativeLabels `mapstructure:"native_labels"`
+	LabelRules          []LabelRule   `mapstructure:"label_rules"`
+	// ClosedIssuesTransition defines how Jira issues are closed when their GitHub issue is closed
+	ClosedIssuesTransition *StatusTransition `mapstructure:"closed_issues_transition"`
+	// ReopenedIssuesTransition defines how Jira issues are reopened when their GitHub issue is reopened
+	ReopenedIssuesTransition *StatusTransition `mapstructure:"reopened_issues_transition"`
+	Log                      Log               `mapstructure:"log"`
+	Report                   Report            `mapstructure:"report"`
+	Metrics                  Metrics           `mapstructure:"metrics"`
+}
+
+// Log defines the logs format (text, logfmt or json) and the minimum level of logged entries
+// (debug, info, warn or error)
+type Log struct {
+	Format string `mapstructure:"format"`
+	Level  string `mapstructure:"level"`
+}
+
+// Report defines where the JSON report of each synchronization run is written, Output is either "stdout" or
+// the path of a file reports are appended to. Reports are disabled if Output is empty.
+type Report struct {
+	Output string `mapstructure:"output"`
+}
+
+// Metrics defines the address of the listener exposing Prometheus metrics on /metrics for the serve and webhook
+// commands. Metrics are not exposed if ListenAddress is empty.
+type Metrics struct {
+	ListenAddress string `mapstructure:"listen_address"`
+}
+
+// UserMapping maps GitHub users to Jira users to synchronize issues reporters and assignees.
+//
+// Users are Jira account IDs, or usernames if UseUsernames is set as required by Jira Server, indexed by GitHub login.
+// Assignees of Jira issues whose GitHub issue has no assignee are only cleared if Unassign is set.
+type UserMapping struct {
+	Users         map[string]string `mapstructure:"users"`
+	LookupByEmail bool              `mapstructure:"lookup_by_email"`
+	FallbackUser  string            `mapstructure:"fallback_user"`
+	UseUsernames  bool              `mapstructure:"use_usernames"`
+	Unassign      bool              `mapstructure:"unassign"`
+}
+
+// enabled returns true if users should be mapped
+func (m UserMapping) enabled() bool {
+	return len(m.Users) > 0 || m.LookupByEmail || m.FallbackUser != ""
+}
+
+// OrphanIssues defines how to handle open Jira issues whose GitHub issue was transferred, deleted or converted
+// to a discussion. Orphan issues are reported in logs, Label, Comment and Transition are optional actions. JQL
+// optionally selects the Jira issues of the repository when the Jira project is shared by several repositories.
+type OrphanIssues struct {
+	Enabled    bool   `mapstructure:"enabled"`
+	Label      string `mapstructure:"label"`
+	Comment    bool   `mapstructure:"comment"`
+	Transition string `mapstructure:"transition"`
+	JQL        string `mapstructure:"jql"`
+}
+
+// NativeLabels defines how GitHub labels are synchronized as native Jira labels. Characters of GitHub labels matching
+// the InvalidCharacters regular expression (white spaces by default) are replaced by Replacement ("_" by default)
+// and Prefix is added to labels. Labels starting with Prefix are considered as managed by the synchronization,
+// without Prefix the state_dir parameter is required to record labels written by the synchronization.
+type NativeLabels struct {
+	Enabled           bool   `mapstructure:"enabled"`
+	Prefix            string `mapstructure:"prefix"`
+	InvalidCharacters string `mapstructure:"invalid_characters"`
+	Replacement       string `mapstructure:"replacement"`
+}
+
+// LabelRule sets the priority, components, fix versions or fields values of Jira issues having a GitHub label equal
+// to Label, starting with Prefix or matching the Regexp regular expression. Values may reference the rest of the
+// label following Prefix as $1 or the submatches of Regexp. When several rules set the priority or the same field,
+// the first matching rule wins. Components added by rules that do not match anymore are only removed if state_dir
+// is set.
+type LabelRule struct {
+	Label       string           `mapstructure:"label"`
+	Prefix      string           `mapstructure:"prefix"`
+	Regexp      string           `mapstructure:"regexp"`
+	Priority    string           `mapstructure:"priority"`
+	Components  []string         `mapstructure:"components"`
+	FixVersions []string         `mapstructure:"fix_versions"`
+	Fields      []LabelRuleField `mapstructure:"fields"`
+}
+
+// LabelRuleField is a value of a Jira field identified by its name or ID
+type LabelRuleField struct {
+	Field string `mapstructure:"field"`
+	Value string `mapstructure:"value"`
 }
 
 // Synchronization allows to link specific github repository to a Jira Board
 type Synchronization struct {
-	GithubOwner           string            `mapstructure:"github_owner"`
-	GithubRepository      string            `mapstructure:"github_repository"`
-	JiraBoardID           int               `mapstructure:"jira_board_id"`
-	ReleaseRenamer        ReleaseRenamer    `mapstructure:"release_renamer"`
-	IssueLabelToType      *IssueLabelToType `mapstructure:"issues_label_to_type"`
-	DefaultJiraComponents []string          `mapstructure:"default_jira_components"`
+	GithubOwner           string             `mapstructure:"github_owner"`
+	GithubRepository      string             `mapstructure:"github_repository"`
+	JiraBoardID           int                `mapstructure:"jira_board_id"`
+	ZenhubWorkspace       string             `mapstructure:"zenhub_workspace"`
+	ReleaseRenamer        ReleaseRenamer     `mapstructure:"release_renamer"`
+	IssueLabelToType      *IssueLabelToType  `mapstructure:"issues_label_to_type"`
+	DefaultJiraComponents []string           `mapstructure:"default_jira_components"`
+	PipelinesToStatuses   []PipelineToStatus `mapstructure:"pipelines_to_statuses"`
+	JiraCommentsToGithub  bool               `mapstructure:"jira_comments_to_github"`
+	RankIssues            bool               `mapstructure:"rank_issues"`
+	MirrorAttachments     bool               `mapstructure:"mirror_attachments"`
+	OrphanIssues          *OrphanIssues      `mapstructure:"orphan_issues"`
+	NativeLabels          *NativeLabels      `mapstructure:"native_labels"`
+	LabelRules            []LabelRule        `mapstructure:"label_rules"`
+	EstimableIssueTypes   []string           `mapstructure:"estimable_issue_types"`
+	SyncInterval          time.Duration      `mapstructure:"sync_interval"`
+	Concurrency           int                `mapstructure:"concurrency"`
+}
+
+// PipelineToStatus maps a ZenHub pipeline to a Jira status.
+//
+// Transitions is an optional ordered list of Jira transitions to apply to reach the status, unavailable ones are
+// skipped. If empty, available transitions are walked until the status is reached.
+type PipelineToStatus struct {
+	Pipeline    string   `mapstructure:"pipeline"`
+	Status      string   `mapstructure:"status"`
+	Transitions []string `mapstructure:"transitions"`
+}
+
+// StatusTransition defines how Jira issues are moved to target statuses.
+//
+// Target statuses are identified by their names using Statuses or by the key of their status category
+// (new, indeterminate or done). Transitions is an optional ordered list of transitions to apply, if empty
+// available transitions are walked until reaching a target status. Fields are values of fields of transitions
+// screens, such as the resolution, indexed by field ID.
+type StatusTransition struct {
+	StatusCategory string            `mapstructure:"status_category"`
+	Statuses       []string          `mapstructure:"statuses"`
+	Transitions    []string          `mapstructure:"transitions"`
+	Fields         map[string]string `mapstructure:"fields"`
 }
 
 type ReleaseRenamer struct {
@@ -36,6 +176,12 @@ type IssueLabelToType struct {
 	LabelsMapping []map[string]string `mapstructure:"labels_mapping"`
 }
 
+// Webhook defines the GitHub webhooks receiver configuration
+type Webhook struct {
+	ListenAddress string `mapstructure:"listen_address"`
+	Secret        string `mapstructure:"secret"`
+}
+
 // JiraAuthentication defines how to connect to Jira
 type JiraAuthentication struct {
 	User     string `mapstructure:"user"`
@@ -63,6 +209,39 @@ func validateConfig(cfg *Config) error {
 		return errors.New("missing jira_authentication.password parameter")
 	}
 
+	if cfg.Concurrency < 0 {
+		return errors.New("concurrency parameter should not be negative")
+	}
+	if cfg.SyncInterval < 0 {
+		return errors.New("sync_interval parameter should not be negative")
+	}
+
+	_, err := logging.ParseLevel(cfg.Log.Level)
+	if err != nil {
+		return errors.Wrap(err, "invalid log.level parameter")
+	}
+	_, err = logging.NewFormatter(cfg.Log.Format)
+	if err != nil {
+		return errors.Wrap(err, "invalid log.format parameter")
+	}
+
+	err = validateStatusTransition(cfg.ClosedIssuesTransition, "closed_issues_transition")
+	if err != nil {
+		return err
+	}
+	err = validateStatusTransition(cfg.ReopenedIssuesTransition, "reopened_issues_transition")
+	if err != nil {
+		return err
+	}
+	err = validateLabelRules(cfg.LabelRules, "label_rules")
+	if err != nil {
+		return err
+	}
+	err = validateNativeLabels(cfg.NativeLabels, cfg.StateDir, "native_labels")
+	if err != nil {
+		return err
+	}
+
 	for i, s := range cfg.Synchronizations {
 		if s.GithubOwner == "" {
 			return errors.Errorf("missing jira_authentication[%d].github_owner parameter", i)
@@ -73,8 +252,79 @@ func validateConfig(cfg *Config) error {
 		if s.JiraBoardID == 0 {
 			return errors.Errorf("missing jira_authentication[%d].jira_board_id parameter", i)
 		}
+		if s.Concurrency < 0 {
+			return errors.Errorf("synchronizations[%d].concurrency parameter should not be negative", i)
+		}
+		if s.SyncInterval < 0 {
+			return errors.Errorf("synchronizations[%d].sync_interval parameter should not be negative", i)
+		}
+		for j, p := range s.PipelinesToStatuses {
+			if p.Pipeline == "" {
+				return errors.Errorf("missing synchronizations[%d].pipelines_to_statuses[%d].pipeline parameter", i, j)
+			}
+			if p.Status == "" {
+				return errors.Errorf("missing synchronizations[%d].pipelines_to_statuses[%d].status parameter", i, j)
+			}
+		}
+		err = validateLabelRules(s.LabelRules, fmt.Sprintf("synchronizations[%d].label_rules", i))
+		if err != nil {
+			return err
+		}
+		err = validateNativeLabels(s.NativeLabels, cfg.StateDir, fmt.Sprintf("synchronizations[%d].native_labels", i))
+		if err != nil {
+			return err
+		}
 
 	}
 
 	return nil
 }
+
+func validateStatusTransition(t *StatusTransition, name string) error {
+	if t == nil {
+		return nil
+	}
+	switch t.StatusCategory {
+	case "", jiralib.StatusCategoryToDo, jiralib.StatusCategoryInProgress, jiralib.StatusCategoryComplete:
+	default:
+		return errors.Errorf("unsupported %s.status_category parameter %q, expecting one of %q, %q or %q", name, t.StatusCategory,
+			jiralib.StatusCategoryToDo, jiralib.StatusCategoryInProgress, jiralib.StatusCategoryComplete)
+	}
+	return nil
+}
+
+func validateLabelRules(rules []LabelRule, name string) error {
+	for i, r := range rules {
+		var patterns int
+		for _, p := range []string{r.Label, r.Prefix, r.Regexp} {
+			if p != "" {
+				patterns++
+			}
+		}
+		if patterns != 1 {
+			return errors.Errorf("%s[%d] should define exactly one of label, prefix or regexp parameters", name, i)
+		}
+		if r.Regexp != "" {
+			_, err := regexp.Compile(r.Regexp)
+			if err != nil {
+				return errors.Wrapf(err, "invalid %s[%d].regexp parameter", name, i)
+			}
+		}
+		if r.Priority == "" && len(r.Components) == 0 && len(r.FixVersions) == 0 && len(r.Fields) == 0 {
+			return errors.Errorf("%s[%d] should define at least one of priority, components, fix_versions or fields parameters", name, i)
+		}
+		for j, f := range r.Fields {
+			if f.Field == "" {
+				return errors.Errorf("missing %s[%d].fields[%d].field parameter", name, i, j)
+			}
+		}
+	}
+	return nil
+}
+
+func validateNativeLabels(nl *NativeLabels, stateDir, name string) error {
+	if nl != nil && nl.Enabled && nl.Prefix == "" && stateDir == "" {
+		return errors.Errorf("%s.prefix parameter is required when state_dir is not set, in order to identify labels managed by the synchronization", name)
+	}
+	return nil
+}
@@ -0,0 +1,82 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+
+	"github.com/ystia/zenhub-jira-sync/pkg/clients/jira"
+)
+
+var issueRefRE = regexp.MustCompile(`^([^/\s]+)/([^#\s]+)#(\d+)$`)
+
+var issueCmd = &cobra.Command{
+	Use:   "issue <owner>/<repo>#<number>",
+	Short: "Synchronize a single ZenHub/GitHub issue to JIRA",
+	Long: `Synchronize a single ZenHub/GitHub issue to JIRA.
+
+The repository should be part of the configured synchronizations. Changes applied to JIRA are printed.`,
+	Args:         cobra.ExactArgs(1),
+	SilenceUsage: true,
+	RunE: func(c *cobra.Command, args []string) error {
+		matches := issueRefRE.FindStringSubmatch(args[0])
+		if matches == nil {
+			return errors.Errorf("invalid issue reference %q, expecting <owner>/<repo>#<number>", args[0])
+		}
+		number, err := strconv.Atoi(matches[3])
+		if err != nil {
+			return errors.Wrapf(err, "invalid issue number in %q", args[0])
+		}
+
+		cfg, err := loadConfig()
+		if err != nil {
+			return err
+		}
+		if dryRun && dryRunFormat != "text" && dryRunFormat != "json" {
+			return errors.Errorf("unsupported dry-run format %q", dryRunFormat)
+		}
+		var syncCfg *Synchronization
+		for i, s := range cfg.Synchronizations {
+			if strings.EqualFold(s.GithubOwner, matches[1]) && strings.EqualFold(s.GithubRepository, matches[2]) {
+				syncCfg = &cfg.Synchronizations[i]
+				break
+			}
+		}
+		if syncCfg == nil {
+			return errors.Errorf("repository %s/%s is not part of the configured synchronizations", matches[1], matches[2])
+		}
+
+		ctx := context.Background()
+		sync, plan, err := createSync(ctx, cfg, *syncCfg, true)
+		if err != nil {
+			return err
+		}
+		plan.Name = fmt.Sprintf("%s/%s#%d", syncCfg.GithubOwner, syncCfg.GithubRepository, number)
+		// This is a targeted repair do not trust the synchronization state
+		sync.ForceUpdate = true
+		err = sync.Issue(ctx, number)
+		if sync.State != nil && !dryRun {
+			saveErr := sync.State.Save()
+			if err == nil {
+				err = saveErr
+			}
+		}
+		printErr := printPlans(os.Stdout, []*jira.Plan{plan}, dryRunFormat)
+		if err != nil {
+			return err
+		}
+		return printErr
+	},
+}
+
+func init() {
+	issueCmd.Flags().BoolVar(&dryRun, "dry-run", false, "Do not apply changes to Jira, print the planned changes instead")
+	issueCmd.Flags().StringVar(&dryRunFormat, "dry-run-format", "text", "Format of the printed changes (text or json)")
+	rootCmd.AddCommand(issueCmd)
+}
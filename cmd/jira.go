@@ -0,0 +1,59 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"github.com/ystia/zenhub-jira-sync/pkg/clients/jira"
+)
+
+var jiraCmd = &cobra.Command{
+	Use:   "jira",
+	Short: "Manage the JIRA project used by synchronizations",
+}
+
+var jiraSetupCmd = &cobra.Command{
+	Use:   "setup",
+	Short: "Create the JIRA custom fields required by synchronizations",
+	Long: `Create the JIRA custom fields required by synchronizations.
+
+Missing GitHub custom fields are created using their default names or the names set in custom_fields, then all of
+them are added to the screens of the project. This command is idempotent, applied changes are printed.
+
+Screens can only be updated on Jira Cloud, on Jira Server fields have to be added to the screens manually.`,
+	Args:         cobra.NoArgs,
+	SilenceUsage: true,
+	RunE: func(c *cobra.Command, args []string) error {
+		cfg, err := loadConfig()
+		if err != nil {
+			return err
+		}
+		jiraClient, err := createJiraClient(cfg)
+		if err != nil {
+			return err
+		}
+		client := &jira.Client{
+			JiraClient:   jiraClient,
+			ProjectKey:   cfg.JiraProjectKey,
+			CustomFields: cfg.CustomFields,
+		}
+		changes, err := client.Setup(dryRun)
+		for _, change := range changes {
+			if dryRun {
+				change = "[dry-run] " + change
+			}
+			fmt.Println(change)
+		}
+		if err == nil && len(changes) == 0 {
+			fmt.Printf("JIRA project %s is already set up\n", cfg.JiraProjectKey)
+		}
+		return err
+	},
+}
+
+func init() {
+	jiraSetupCmd.Flags().BoolVar(&dryRun, "dry-run", false, "Do not apply changes to Jira, print the planned changes instead")
+	jiraCmd.AddCommand(jiraSetupCmd)
+	rootCmd.AddCommand(jiraCmd)
+}
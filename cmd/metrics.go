@@ -0,0 +1,22 @@
+package cmd
+
+import (
+	"net/http"
+
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"github.com/sirupsen/logrus"
+)
+
+// startMetricsListener exposes metrics in background if a listen address is configured
+func startMetricsListener(cfg *Config) {
+	if cfg.Metrics.ListenAddress == "" {
+		return
+	}
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", promhttp.Handler())
+	go func() {
+		logrus.Infof("Exposing metrics on %s/metrics", cfg.Metrics.ListenAddress)
+		err := http.ListenAndServe(cfg.Metrics.ListenAddress, mux)
+		logrus.WithError(err).Errorf("Metrics listener failure")
+	}()
+}
@@ -2,14 +2,21 @@ package cmd
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
+	"io"
+	"net/http"
 	"os"
 	"regexp"
+	"strings"
+	"sync"
+	"time"
 
 	jiralib "github.com/andygrunwald/go-jira"
 	gh "github.com/google/go-github/v24/github"
 	"github.com/mitchellh/go-homedir"
 	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
 	"golang.org/x/oauth2"
@@ -17,7 +24,11 @@ import (
 	"github.com/ystia/zenhub-jira-sync/pkg"
 	"github.com/ystia/zenhub-jira-sync/pkg/clients/github"
 	"github.com/ystia/zenhub-jira-sync/pkg/clients/jira"
+	"github.com/ystia/zenhub-jira-sync/pkg/clients/retry"
 	"github.com/ystia/zenhub-jira-sync/pkg/clients/zenhub"
+	"github.com/ystia/zenhub-jira-sync/pkg/logging"
+	"github.com/ystia/zenhub-jira-sync/pkg/metrics"
+	"github.com/ystia/zenhub-jira-sync/pkg/state"
 )
 
 var rootCmd = &cobra.Command{
@@ -25,20 +36,26 @@ var rootCmd = &cobra.Command{
 	Short:        "Synchronize ZenHub/GitHub issues to JIRA",
 	SilenceUsage: true,
 	RunE: func(c *cobra.Command, args []string) error {
-		cfg := new(Config)
-
-		viper.Unmarshal(cfg)
-
-		err := validateConfig(cfg)
+		cfg, err := loadConfig()
 		if err != nil {
 			return err
 		}
+		if dryRun && dryRunFormat != "text" && dryRunFormat != "json" {
+			return errors.Errorf("unsupported dry-run format %q", dryRunFormat)
+		}
+		plans := make([]*jira.Plan, 0)
 		for _, s := range cfg.Synchronizations {
-			err := syncRepository(cfg, s)
+			plan, err := syncRepository(context.Background(), cfg, s)
+			if plan != nil {
+				plans = append(plans, plan)
+			}
 			if err != nil {
 				return err
 			}
 		}
+		if dryRun {
+			return printPlans(os.Stdout, plans, dryRunFormat)
+		}
 		return nil
 	},
 }
@@ -46,10 +63,13 @@ var rootCmd = &cobra.Command{
 func init() {
 	cobra.OnInitialize(initConfig)
 	rootCmd.PersistentFlags().StringVarP(&cfgFile, "config", "c", "", "Config file (default is /etc/zh-jira-sync/zh-jira-sync.[json|yaml])")
-
+	rootCmd.Flags().BoolVar(&dryRun, "dry-run", false, "Do not apply changes to Jira, print the planned changes instead")
+	rootCmd.Flags().StringVar(&dryRunFormat, "dry-run-format", "text", "Format of the planned changes printed in dry-run mode (text or json)")
 }
 
 var cfgFile string
+var dryRun bool
+var dryRunFormat string
 
 func initConfig() {
 	// Don't forget to read config either from cfgFile or from home directory!
@@ -60,8 +80,7 @@ func initConfig() {
 		// Find home directory.
 		home, err := homedir.Dir()
 		if err != nil {
-			fmt.Println(err)
-			os.Exit(1)
+			logrus.WithError(err).Fatalf("Can't find home directory")
 		}
 
 		// Search config in home directory with name ".cobra" (without extension).
@@ -74,52 +93,197 @@ func initConfig() {
 	}
 
 	if err := viper.ReadInConfig(); err != nil {
-		fmt.Println("Can't read config:", err)
-		os.Exit(1)
+		logrus.WithError(err).Fatalf("Can't read config")
+	}
+}
+
+// loadConfig reads and validates the configuration
+func loadConfig() (*Config, error) {
+	cfg := new(Config)
+
+	viper.Unmarshal(cfg)
+
+	err := validateConfig(cfg)
+	if err != nil {
+		return cfg, err
 	}
+	// Validated above
+	logging.Configure(logrus.StandardLogger(), cfg.Log.Format, cfg.Log.Level)
+	return cfg, nil
 }
 
 func createGithubClient(ctx context.Context, cfg *Config) *gh.Client {
-	ts := oauth2.StaticTokenSource(
+	// oauth2 uses the http client from the context as base
+	ctx = context.WithValue(ctx, oauth2.HTTPClient, &http.Client{Transport: retry.NewTransport(metrics.NewTransport(metrics.BackendGitHub, nil))})
+	tc := oauth2.NewClient(ctx, githubTokenSource(cfg))
+	return gh.NewClient(tc)
+}
+
+func githubTokenSource(cfg *Config) oauth2.TokenSource {
+	return oauth2.StaticTokenSource(
 		&oauth2.Token{AccessToken: cfg.GithubAPIToken},
 	)
-	tc := oauth2.NewClient(ctx, ts)
-	return gh.NewClient(tc)
+}
+
+// createAttachmentsFetcher creates an HTTP client downloading GitHub attachments, authenticated on GitHub hosts only
+func createAttachmentsFetcher(cfg *Config) *http.Client {
+	return &http.Client{Transport: &github.AttachmentsTransport{
+		Authenticated: &oauth2.Transport{Source: githubTokenSource(cfg), Base: retry.NewTransport(nil)},
+		Base:          retry.NewTransport(nil),
+	}}
 }
 
 func createJiraClient(cfg *Config) (*jiralib.Client, error) {
 	tp := jiralib.BasicAuthTransport{
-		Username: cfg.JiraAuthentication.User,
-		Password: cfg.JiraAuthentication.Password,
+		Username:  cfg.JiraAuthentication.User,
+		Password:  cfg.JiraAuthentication.Password,
+		Transport: retry.NewTransport(metrics.NewTransport(metrics.BackendJira, nil)),
 	}
 	jiraClient, err := jiralib.NewClient(tp.Client(), cfg.JiraURI)
 	return jiraClient, errors.Wrapf(err, "failed to create jira client")
 }
 
-func syncRepository(cfg *Config, s Synchronization) error {
-	ctx := context.Background()
+// syncRepository synchronizes a single repository
+//
+// In dry-run mode the returned plan contains changes that would have been applied to Jira
+func syncRepository(ctx context.Context, cfg *Config, s Synchronization) (*jira.Plan, error) {
+	sync, plan, err := createSync(ctx, cfg, s, dryRun)
+	if err != nil {
+		return plan, err
+	}
+	return plan, runSync(ctx, cfg, s, sync)
+}
+
+// createSync creates the synchronization tool of a single repository
+//
+// If recordChanges is true or in dry-run mode the returned plan will contain changes applied to Jira,
+// or that would have been applied in dry-run mode.
+func createSync(ctx context.Context, cfg *Config, s Synchronization, recordChanges bool) (*pkg.Sync, *jira.Plan, error) {
 	jiraClient, err := createJiraClient(cfg)
 	if err != nil {
-		return err
+		return nil, nil, err
 	}
 
 	syncJiraClient := &jira.Client{
-		JiraClient: jiraClient,
-		BoardID:    s.JiraBoardID,
-		ProjectKey: cfg.JiraProjectKey,
+		JiraClient:          jiraClient,
+		BoardID:             s.JiraBoardID,
+		ProjectKey:          cfg.JiraProjectKey,
+		UseUsernames:        cfg.UserMapping.UseUsernames,
+		EstimableIssueTypes: s.EstimableIssueTypes,
+		CustomFields:        cfg.CustomFields,
+	}
+	if len(syncJiraClient.EstimableIssueTypes) == 0 {
+		// If not found a synchronization level look at global level
+		syncJiraClient.EstimableIssueTypes = cfg.EstimableIssueTypes
 	}
 	err = syncJiraClient.Init()
 	if err != nil {
-		return err
+		return nil, nil, err
 	}
 
 	sync := &pkg.Sync{
-		GithubClient: &github.Client{
-			GHClient: createGithubClient(ctx, cfg),
-			Owner:    s.GithubOwner,
-			Repo:     s.GithubRepository,
+		GithubClient: &github.InstrumentedClient{
+			API: &github.Client{
+				GHClient: createGithubClient(ctx, cfg),
+				Owner:    s.GithubOwner,
+				Repo:     s.GithubRepository,
+			},
+			OnCall: func(method string, duration time.Duration, err error) {
+				metrics.ObserveAPICall(metrics.BackendGitHub, method, duration, err)
+			},
 		},
-		JiraClient: syncJiraClient,
+		JiraClient: &jira.InstrumentedClient{
+			API: syncJiraClient,
+			OnCall: func(method string, duration time.Duration, err error) {
+				metrics.ObserveAPICall(metrics.BackendJira, method, duration, err)
+			},
+		},
+		Logger: logrus.WithField("repo", fmt.Sprintf("%s/%s", s.GithubOwner, s.GithubRepository)),
+	}
+	if estimableIssueTypes := syncJiraClient.GetEstimableIssueTypes(); len(estimableIssueTypes) > 0 {
+		sync.Logger.Debugf("Issue types supporting estimation: %s", strings.Join(estimableIssueTypes, ", "))
+	} else {
+		sync.Logger.Warnf("No issue type supports estimation on Jira board %d, estimates will not be synchronized", s.JiraBoardID)
+	}
+	var plan *jira.Plan
+	if recordChanges || dryRun {
+		recordingClient := jira.NewRecordingClient(sync.JiraClient, fmt.Sprintf("%s/%s", s.GithubOwner, s.GithubRepository), dryRun)
+		plan = recordingClient.Plan
+		sync.JiraClient = recordingClient
+		sync.GithubClient = &github.RecordingClient{
+			API:    sync.GithubClient,
+			DryRun: dryRun,
+			OnWrite: func(operation, target, content string) {
+				recordingClient.Record(operation, jira.PlanEntityGitHubComment, target, map[string]*jira.FieldChange{
+					"body": {To: content},
+				})
+			},
+		}
+	}
+	sync.JiraCommentsToGithub = s.JiraCommentsToGithub
+	for _, other := range cfg.Synchronizations {
+		sync.SynchronizedRepositories = append(sync.SynchronizedRepositories, fmt.Sprintf("%s/%s", other.GithubOwner, other.GithubRepository))
+	}
+	sync.RankIssues = s.RankIssues
+	if sync.RankIssues && syncJiraClient.GetCustomFieldID(jira.CFNameRank) == "" {
+		return nil, nil, errors.Errorf("rank_issues is enabled for repository %s/%s but the Jira rank field was not found, set custom_fields.rank", s.GithubOwner, s.GithubRepository)
+	}
+	if cfg.UserMapping.enabled() {
+		sync.UserMapping = &pkg.UserMapping{
+			Users:         make(map[string]string, len(cfg.UserMapping.Users)),
+			LookupByEmail: cfg.UserMapping.LookupByEmail,
+			Fallback:      cfg.UserMapping.FallbackUser,
+			Unassign:      cfg.UserMapping.Unassign,
+		}
+		for login, id := range cfg.UserMapping.Users {
+			sync.UserMapping.Users[strings.ToLower(login)] = id
+		}
+	}
+	if s.OrphanIssues == nil {
+		// If not found a synchronization level look at global level
+		s.OrphanIssues = cfg.OrphanIssues
+	}
+	if s.OrphanIssues != nil && s.OrphanIssues.Enabled {
+		sync.OrphanIssues = &pkg.OrphanIssues{
+			Label:      s.OrphanIssues.Label,
+			Comment:    s.OrphanIssues.Comment,
+			Transition: s.OrphanIssues.Transition,
+			JQL:        s.OrphanIssues.JQL,
+		}
+	}
+	if s.NativeLabels == nil {
+		// If not found a synchronization level look at global level
+		s.NativeLabels = cfg.NativeLabels
+	}
+	if s.NativeLabels != nil && s.NativeLabels.Enabled {
+		sync.NativeLabels = &pkg.NativeLabels{
+			Prefix:      s.NativeLabels.Prefix,
+			Replacement: s.NativeLabels.Replacement,
+		}
+		if sync.NativeLabels.Replacement == "" {
+			sync.NativeLabels.Replacement = "_"
+		}
+		if s.NativeLabels.InvalidCharacters != "" {
+			sync.NativeLabels.InvalidCharacters, err = regexp.Compile(s.NativeLabels.InvalidCharacters)
+			if err != nil {
+				return nil, nil, errors.Wrapf(err, "failed to compile native labels invalid characters regexp for repository %s/%s", s.GithubOwner, s.GithubRepository)
+			}
+		}
+	}
+	if s.LabelRules == nil {
+		// If not found a synchronization level look at global level
+		s.LabelRules = cfg.LabelRules
+	}
+	sync.LabelRules, err = newLabelRules(s.LabelRules, syncJiraClient)
+	if err != nil {
+		return nil, nil, errors.Wrapf(err, "invalid label rules for repository %s/%s", s.GithubOwner, s.GithubRepository)
+	}
+	if s.MirrorAttachments {
+		sync.AttachmentsFetcher = createAttachmentsFetcher(cfg)
+	}
+	sync.Concurrency = s.Concurrency
+	if sync.Concurrency == 0 {
+		sync.Concurrency = cfg.Concurrency
 	}
 
 	if s.IssueLabelToType == nil {
@@ -152,9 +316,22 @@ func syncRepository(cfg *Config, s Synchronization) error {
 
 	ghRepo, err := sync.GithubClient.GetRepository(ctx)
 	if err != nil {
-		return err
+		return nil, plan, err
+	}
+	zhClient := zenhub.NewClient(cfg.ZenhubAPIToken, *ghRepo.ID)
+	zhClient.HTTPClient = zenhub.NewHTTPClient(metrics.NewTransport(metrics.BackendZenHub, nil))
+	if s.ZenhubWorkspace != "" {
+		zhClient.Workspace, err = resolveZenhubWorkspace(zhClient, s.ZenhubWorkspace)
+		if err != nil {
+			return nil, plan, errors.Wrapf(err, "invalid ZenHub workspace for repository %s/%s", s.GithubOwner, s.GithubRepository)
+		}
+	}
+	sync.ZenhubClient = &zenhub.InstrumentedClient{
+		API: zhClient,
+		OnCall: func(method string, duration time.Duration, err error) {
+			metrics.ObserveAPICall(metrics.BackendZenHub, method, duration, err)
+		},
 	}
-	sync.ZenhubClient = zenhub.NewClient(cfg.ZenhubAPIToken, *ghRepo.ID)
 
 	if s.ReleaseRenamer.Source == "" {
 		s.ReleaseRenamer.Source = "^(.*)$"
@@ -165,13 +342,170 @@ func syncRepository(cfg *Config, s Synchronization) error {
 	}
 	sync.ReleaseNameRE, err = regexp.Compile(s.ReleaseRenamer.Source)
 	if err != nil {
-		return errors.Wrapf(err, "failed to compile release regexp for repository %s/%s", s.GithubOwner, s.GithubRepository)
+		return nil, plan, errors.Wrapf(err, "failed to compile release regexp for repository %s/%s", s.GithubOwner, s.GithubRepository)
 	}
 	sync.VersionNameRename = s.ReleaseRenamer.Target
 
 	sync.DefaultJiraComponents = getSyncJiraComponents(cfg.DefaultJiraComponents, s.DefaultJiraComponents)
 
-	return sync.All(ctx)
+	for _, p := range s.PipelinesToStatuses {
+		sync.PipelinesToStatuses = append(sync.PipelinesToStatuses, pkg.PipelineToStatus{
+			Pipeline: p.Pipeline,
+			Transition: pkg.StatusTransition{
+				Statuses:    []string{p.Status},
+				Transitions: p.Transitions,
+			},
+		})
+	}
+
+	sync.ClosedIssuesTransition = newStatusTransition(cfg.ClosedIssuesTransition, jiralib.StatusCategoryComplete)
+	sync.ReopenedIssuesTransition = newStatusTransition(cfg.ReopenedIssuesTransition, jiralib.StatusCategoryToDo)
+
+	if cfg.StateDir != "" {
+		sync.State, err = state.Open(cfg.StateDir, fmt.Sprintf("%s_%s", s.GithubOwner, s.GithubRepository))
+		if err != nil {
+			return nil, plan, err
+		}
+	}
+
+	return sync, plan, nil
+}
+
+// newStatusTransition converts a status transition configuration, statuses of the given category are targeted
+// if the configuration does not define them. It returns nil if t is nil.
+func newStatusTransition(t *StatusTransition, defaultCategory string) *pkg.StatusTransition {
+	if t == nil {
+		return nil
+	}
+	st := &pkg.StatusTransition{
+		StatusCategory: t.StatusCategory,
+		Statuses:       t.Statuses,
+		Transitions:    t.Transitions,
+		Fields:         t.Fields,
+	}
+	if st.StatusCategory == "" {
+		st.StatusCategory = defaultCategory
+	}
+	return st
+}
+
+// newLabelRules converts label rules configurations, patterns are compiled and fields are resolved using the given
+// Jira client
+func newLabelRules(rules []LabelRule, jiraClient *jira.Client) ([]pkg.LabelRule, error) {
+	labelRules := make([]pkg.LabelRule, 0, len(rules))
+	for i, r := range rules {
+		var pattern string
+		switch {
+		case r.Label != "":
+			pattern = "^" + regexp.QuoteMeta(r.Label) + "$"
+		case r.Prefix != "":
+			pattern = "^" + regexp.QuoteMeta(r.Prefix) + "(.*)$"
+		default:
+			pattern = r.Regexp
+		}
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to compile pattern of rule %d", i)
+		}
+		labelRule := pkg.LabelRule{
+			Pattern:     re,
+			Priority:    r.Priority,
+			Components:  r.Components,
+			FixVersions: r.FixVersions,
+		}
+		for _, f := range r.Fields {
+			field, err := jiraClient.FindField(f.Field)
+			if err != nil {
+				return nil, errors.Wrapf(err, "invalid field of rule %d", i)
+			}
+			labelRule.Fields = append(labelRule.Fields, pkg.LabelRuleField{
+				ID:    field.ID,
+				Type:  field.Schema.Type,
+				Value: f.Value,
+			})
+		}
+		labelRules = append(labelRules, labelRule)
+	}
+	return labelRules, nil
+}
+
+// resolveZenhubWorkspace returns the ID of a workspace of the repository identified by its ID or its name
+func resolveZenhubWorkspace(zhClient zenhub.API, workspace string) (string, error) {
+	workspaces, err := zhClient.GetWorkspaces()
+	if err != nil {
+		return "", err
+	}
+	names := make([]string, 0, len(workspaces))
+	for _, w := range workspaces {
+		if w.ID == workspace || strings.EqualFold(w.Name, workspace) {
+			return w.ID, nil
+		}
+		names = append(names, fmt.Sprintf("%q", w.Name))
+	}
+	return "", errors.Errorf("workspace %q not found, available workspaces are: %s", workspace, strings.Join(names, ", "))
+}
+
+// runSync runs a synchronization, saves its state and writes its report
+func runSync(ctx context.Context, cfg *Config, s Synchronization, sync *pkg.Sync) error {
+	sync.Report = pkg.NewReport(fmt.Sprintf("%s/%s", s.GithubOwner, s.GithubRepository))
+	err := sync.All(ctx)
+	if sync.State != nil && !dryRun {
+		// Save state even on failure as it only contains successfully synchronized issues
+		saveErr := sync.State.Save()
+		if err == nil {
+			err = saveErr
+		}
+	}
+	sync.Report.Finish(err)
+	metrics.ObserveSync(sync.Report.Repository, sync.Report.StartedAt, sync.Report.FinishedAt, err, sync.Report.Outcomes())
+	if cfg.Report.Output != "" {
+		reportErr := writeReport(cfg.Report.Output, sync.Report)
+		if err == nil {
+			err = reportErr
+		}
+	}
+	return err
+}
+
+// reportsLock prevents concurrent synchronizations to interleave their reports
+var reportsLock sync.Mutex
+
+// writeReport writes a synchronization report to the standard output or appends it to the given file
+func writeReport(output string, report *pkg.Report) error {
+	reportsLock.Lock()
+	defer reportsLock.Unlock()
+	if output == "stdout" {
+		return report.WriteJSON(os.Stdout)
+	}
+	f, err := os.OpenFile(output, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return errors.Wrapf(err, "failed to open report file %q", output)
+	}
+	err = report.WriteJSON(f)
+	closeErr := f.Close()
+	if err == nil && closeErr != nil {
+		err = errors.Wrapf(closeErr, "failed to write report file %q", output)
+	}
+	return err
+}
+
+func printPlans(w io.Writer, plans []*jira.Plan, format string) error {
+	switch format {
+	case "json":
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return errors.Wrap(enc.Encode(plans), "failed to print dry-run plans")
+	case "text":
+		for _, plan := range plans {
+			err := plan.WriteText(w)
+			if err != nil {
+				return errors.Wrap(err, "failed to print dry-run plans")
+			}
+		}
+		return nil
+	default:
+		return errors.Errorf("unsupported dry-run format %q", format)
+	}
 }
 
 func getSyncJiraComponents(globalComponents []string, repositoryComponents []string) []string {
@@ -191,7 +525,6 @@ func getSyncJiraComponents(globalComponents []string, repositoryComponents []str
 // Execute runs the root command
 func Execute() {
 	if err := rootCmd.Execute(); err != nil {
-		fmt.Println(err)
-		os.Exit(1)
+		logrus.Fatal(err)
 	}
 }
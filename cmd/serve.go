@@ -0,0 +1,171 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+
+	"github.com/ystia/zenhub-jira-sync/pkg"
+)
+
+const (
+	defaultSyncInterval = 15 * time.Minute
+	minRetryDelay       = 30 * time.Second
+	maxRetryDelay       = time.Hour
+)
+
+var serveCmd = &cobra.Command{
+	Use:   "serve",
+	Short: "Periodically synchronize ZenHub/GitHub issues to JIRA",
+	Long: `Periodically synchronize ZenHub/GitHub issues to JIRA.
+
+Each synchronization runs on its own interval (sync_interval), failing synchronizations are retried with an exponential backoff.
+The first SIGINT or SIGTERM signal waits for running synchronizations to finish, the second one aborts them.`,
+	SilenceUsage: true,
+	RunE: func(c *cobra.Command, args []string) error {
+		cfg, err := loadConfig()
+		if err != nil {
+			return err
+		}
+
+		startMetricsListener(cfg)
+
+		// stopCtx stops scheduling new synchronizations while runCtx aborts running ones
+		stopCtx, stop := context.WithCancel(context.Background())
+		defer stop()
+		runCtx, abort := context.WithCancel(context.Background())
+		defer abort()
+		signals := make(chan os.Signal, 2)
+		signal.Notify(signals, syscall.SIGINT, syscall.SIGTERM)
+		defer signal.Stop(signals)
+		go handleShutdownSignals(signals, stop, abort)
+
+		locks := make(map[string]*sync.Mutex)
+		var wg sync.WaitGroup
+		for _, s := range cfg.Synchronizations {
+			repo := fmt.Sprintf("%s/%s", s.GithubOwner, s.GithubRepository)
+			if locks[repo] == nil {
+				locks[repo] = new(sync.Mutex)
+			}
+			sched := scheduler{
+				interval:   syncInterval(cfg, s),
+				minBackoff: minRetryDelay,
+				maxBackoff: maxRetryDelay,
+			}
+			wg.Add(1)
+			go func(s Synchronization, lock *sync.Mutex, sched scheduler) {
+				defer wg.Done()
+				serveRepository(stopCtx, runCtx, cfg, s, lock, sched)
+			}(s, locks[repo], sched)
+		}
+		wg.Wait()
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(serveCmd)
+}
+
+// handleShutdownSignals calls stop on the first signal and abort on the second one
+func handleShutdownSignals(signals <-chan os.Signal, stop, abort func()) {
+	<-signals
+	logrus.Infof("Shutting down, waiting for running synchronizations to finish")
+	stop()
+	<-signals
+	logrus.Warnf("Aborting running synchronizations")
+	abort()
+}
+
+// syncInterval returns the interval between runs of the given synchronization
+func syncInterval(cfg *Config, s Synchronization) time.Duration {
+	if s.SyncInterval != 0 {
+		return s.SyncInterval
+	}
+	if cfg.SyncInterval != 0 {
+		return cfg.SyncInterval
+	}
+	return defaultSyncInterval
+}
+
+// serveRepository periodically synchronizes a repository until stopCtx is cancelled.
+//
+// lock prevents overlapping runs on the same repository.
+func serveRepository(stopCtx, runCtx context.Context, cfg *Config, s Synchronization, lock *sync.Mutex, sched scheduler) {
+	logger := logrus.WithField("repo", fmt.Sprintf("%s/%s", s.GithubOwner, s.GithubRepository))
+	// Keep the synchronization tool between runs to benefit from its caches
+	var sync *pkg.Sync
+	sched.run(stopCtx, logger, func() error {
+		lock.Lock()
+		defer lock.Unlock()
+		var err error
+		if sync == nil {
+			sync, _, err = createSync(runCtx, cfg, s, false)
+			if err != nil {
+				return err
+			}
+		}
+		logger.Infof("Starting synchronization")
+		return runSync(runCtx, cfg, s, sync)
+	})
+}
+
+// scheduler runs a function periodically, failing runs are retried with an exponential backoff
+type scheduler struct {
+	interval   time.Duration
+	minBackoff time.Duration
+	maxBackoff time.Duration
+}
+
+// run calls fn until stopCtx is cancelled, stopCtx does not interrupt a running call
+func (sc scheduler) run(stopCtx context.Context, logger *logrus.Entry, fn func() error) {
+	var failures int
+	for {
+		err := fn()
+		if err != nil {
+			failures++
+		} else {
+			failures = 0
+		}
+		delay := sc.nextDelay(failures)
+		if err != nil {
+			logger.WithError(err).Errorf("Synchronization failed (%d consecutive failure(s)), retrying in %v", failures, delay)
+		} else {
+			logger.Infof("Synchronization succeeded, next one in %v", delay)
+		}
+
+		select {
+		case <-stopCtx.Done():
+			return
+		case <-time.After(delay):
+		}
+	}
+}
+
+// nextDelay returns the delay before the next run given the number of consecutive failures, the interval is used
+// without failures and an exponential backoff delay with jitter otherwise
+func (sc scheduler) nextDelay(failures int) time.Duration {
+	if failures == 0 {
+		return sc.interval
+	}
+	delay := sc.maxBackoff
+	if failures < 32 {
+		delay = sc.minBackoff << uint(failures-1)
+	}
+	if delay > sc.maxBackoff || delay <= 0 {
+		delay = sc.maxBackoff
+	}
+	// Add up to 20% of jitter to prevent failing synchronizations to retry at the same time
+	if jitter := int64(delay) / 5; jitter > 0 {
+		delay += time.Duration(rand.Int63n(jitter))
+	}
+	return delay
+}
@@ -0,0 +1,110 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"os"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+func TestSchedulerNextDelay(t *testing.T) {
+	sched := scheduler{interval: time.Minute, minBackoff: time.Second, maxBackoff: 10 * time.Second}
+	tests := []struct {
+		name     string
+		failures int
+		min      time.Duration
+		max      time.Duration
+	}{
+		{"NoFailure", 0, time.Minute, time.Minute},
+		{"FirstFailure", 1, time.Second, 1200 * time.Millisecond},
+		{"ThirdFailure", 3, 4 * time.Second, 4800 * time.Millisecond},
+		{"MaxBackoff", 10, 10 * time.Second, 12 * time.Second},
+		{"Overflow", 100, 10 * time.Second, 12 * time.Second},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if delay := sched.nextDelay(tt.failures); delay < tt.min || delay > tt.max {
+				t.Errorf("nextDelay(%d) = %v, expecting a delay between %v and %v", tt.failures, delay, tt.min, tt.max)
+			}
+		})
+	}
+}
+
+func TestSchedulerRun(t *testing.T) {
+	sched := scheduler{interval: time.Millisecond, minBackoff: time.Millisecond, maxBackoff: 2 * time.Millisecond}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	var calls int
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		sched.run(ctx, logrus.NewEntry(logrus.StandardLogger()), func() error {
+			calls++
+			switch {
+			case calls < 3:
+				return errors.New("failure")
+			case calls == 5:
+				// Stopping does not interrupt a running synchronization
+				cancel()
+			}
+			return nil
+		})
+	}()
+	select {
+	case <-done:
+	case <-time.After(10 * time.Second):
+		t.Fatal("scheduler did not stop")
+	}
+	if calls != 5 {
+		t.Errorf("unexpected number of runs %d, expecting 5", calls)
+	}
+}
+
+func TestSyncInterval(t *testing.T) {
+	tests := []struct {
+		name     string
+		global   time.Duration
+		sync     time.Duration
+		expected time.Duration
+	}{
+		{"Default", 0, 0, defaultSyncInterval},
+		{"Global", time.Hour, 0, time.Hour},
+		{"Synchronization", time.Hour, time.Minute, time.Minute},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := syncInterval(&Config{SyncInterval: tt.global}, Synchronization{SyncInterval: tt.sync}); got != tt.expected {
+				t.Errorf("syncInterval() = %v, expecting %v", got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestHandleShutdownSignals(t *testing.T) {
+	signals := make(chan os.Signal, 2)
+	stopped := make(chan struct{})
+	aborted := make(chan struct{})
+	go handleShutdownSignals(signals, func() { close(stopped) }, func() { close(aborted) })
+
+	signals <- syscall.SIGTERM
+	select {
+	case <-stopped:
+	case <-time.After(10 * time.Second):
+		t.Fatal("first signal did not stop scheduling")
+	}
+	select {
+	case <-aborted:
+		t.Fatal("first signal should not abort running synchronizations")
+	default:
+	}
+	signals <- syscall.SIGINT
+	select {
+	case <-aborted:
+	case <-time.After(10 * time.Second):
+		t.Fatal("second signal did not abort running synchronizations")
+	}
+}
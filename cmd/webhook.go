@@ -0,0 +1,244 @@
+package cmd
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"os/signal"
+	"strings"
+	"sync"
+	"syscall"
+
+	gh "github.com/google/go-github/v24/github"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+
+	"github.com/ystia/zenhub-jira-sync/pkg"
+)
+
+const (
+	defaultWebhookListenAddress = ":8080"
+	webhookSignatureHeader      = "X-Hub-Signature-256"
+	webhookEventHeader          = "X-GitHub-Event"
+	webhookMaxPayloadSize       = 25 * 1024 * 1024
+	webhookQueueSize            = 100
+)
+
+var webhookCmd = &cobra.Command{
+	Use:   "webhook",
+	Short: "Receive GitHub webhooks to synchronize issues, comments and milestones to JIRA in near-real-time",
+	Long: `Receive GitHub webhooks to synchronize issues, comments and milestones to JIRA in near-real-time.
+
+Supported events are issues, issue_comment and milestone. Payloads are verified using the X-Hub-Signature-256 header
+and the webhook.secret configuration parameter. Events are processed asynchronously, one at a time per repository,
+and applied to all synchronizations of their repository.`,
+	SilenceUsage: true,
+	RunE: func(c *cobra.Command, args []string) error {
+		cfg, err := loadConfig()
+		if err != nil {
+			return err
+		}
+		if cfg.Webhook.Secret == "" {
+			return errors.New("missing webhook.secret parameter")
+		}
+		listenAddress := cfg.Webhook.ListenAddress
+		if listenAddress == "" {
+			listenAddress = defaultWebhookListenAddress
+		}
+
+		startMetricsListener(cfg)
+
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		receiver := &webhookReceiver{
+			secret: []byte(cfg.Webhook.Secret),
+			queues: make(map[string]chan webhookJob),
+		}
+		// Events of a repository are applied to all of its synchronizations
+		syncs := make(map[string][]*pkg.Sync)
+		for _, s := range cfg.Synchronizations {
+			repo := strings.ToLower(fmt.Sprintf("%s/%s", s.GithubOwner, s.GithubRepository))
+			sync, _, err := createSync(ctx, cfg, s, false)
+			if err != nil {
+				return err
+			}
+			syncs[repo] = append(syncs[repo], sync)
+		}
+		var wg sync.WaitGroup
+		for repo := range syncs {
+			queue := make(chan webhookJob, webhookQueueSize)
+			receiver.queues[repo] = queue
+			wg.Add(1)
+			go func(repo string, syncs []*pkg.Sync, queue chan webhookJob) {
+				defer wg.Done()
+				processWebhookJobs(ctx, repo, syncs, queue)
+			}(repo, syncs[repo], queue)
+		}
+
+		server := &http.Server{Addr: listenAddress, Handler: receiver}
+		signals := make(chan os.Signal, 1)
+		signal.Notify(signals, syscall.SIGINT, syscall.SIGTERM)
+		defer signal.Stop(signals)
+		// done is closed once running handlers returned, queues could not be written anymore
+		done := make(chan struct{})
+		go func() {
+			<-signals
+			logrus.Infof("Shutting down, waiting for queued events to be processed")
+			server.Shutdown(ctx)
+			close(done)
+		}()
+
+		logrus.Infof("Listening for GitHub webhooks on %s", listenAddress)
+		err = server.ListenAndServe()
+		if err != http.ErrServerClosed {
+			return errors.Wrap(err, "webhook server failure")
+		}
+		// ListenAndServe returns as soon as the shutdown begins
+		<-done
+		for _, queue := range receiver.queues {
+			close(queue)
+		}
+		wg.Wait()
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(webhookCmd)
+}
+
+// webhookJob is a synchronization triggered by a webhook event
+type webhookJob struct {
+	description string
+	run         func(ctx context.Context, sync *pkg.Sync) error
+}
+
+// processWebhookJobs runs jobs of a repository one at a time on each of its synchronizations until the queue is closed
+func processWebhookJobs(ctx context.Context, repo string, syncs []*pkg.Sync, queue <-chan webhookJob) {
+	logger := logrus.WithField("repo", repo)
+	for job := range queue {
+		for _, sync := range syncs {
+			logger.Infof("Synchronizing %s", job.description)
+			err := job.run(ctx, sync)
+			if err != nil {
+				logger.WithError(err).Errorf("Failed to synchronize %s", job.description)
+			}
+			if sync.State != nil {
+				err = sync.State.Save()
+				if err != nil {
+					logger.Errorf("%v", err)
+				}
+			}
+		}
+	}
+}
+
+// webhookReceiver is an http.Handler that validates GitHub webhooks and queues related synchronizations
+type webhookReceiver struct {
+	secret []byte
+	// queues are indexed by lower-cased repository full name and are never modified while serving requests
+	queues map[string]chan webhookJob
+}
+
+func (wr *webhookReceiver) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	payload, err := ioutil.ReadAll(http.MaxBytesReader(w, r.Body, webhookMaxPayloadSize))
+	if err != nil {
+		http.Error(w, "failed to read payload", http.StatusBadRequest)
+		return
+	}
+	if !validateWebhookSignature(r.Header.Get(webhookSignatureHeader), payload, wr.secret) {
+		http.Error(w, "invalid signature", http.StatusUnauthorized)
+		return
+	}
+	eventType := r.Header.Get(webhookEventHeader)
+	if eventType == "ping" {
+		w.WriteHeader(http.StatusOK)
+		return
+	}
+	event, err := gh.ParseWebHook(eventType, payload)
+	if err != nil {
+		http.Error(w, "unsupported event", http.StatusBadRequest)
+		return
+	}
+
+	var repo *gh.Repository
+	var job webhookJob
+	switch e := event.(type) {
+	case *gh.IssuesEvent:
+		repo = e.GetRepo()
+		job = issueWebhookJob(e.GetIssue())
+	case *gh.IssueCommentEvent:
+		if e.GetIssue().IsPullRequest() {
+			w.WriteHeader(http.StatusOK)
+			return
+		}
+		repo = e.GetRepo()
+		job = issueWebhookJob(e.GetIssue())
+	case *gh.MilestoneEvent:
+		if e.GetAction() == "deleted" {
+			w.WriteHeader(http.StatusOK)
+			return
+		}
+		repo = e.GetRepo()
+		milestone := e.GetMilestone()
+		job = webhookJob{
+			description: fmt.Sprintf("milestone %q", milestone.GetTitle()),
+			run: func(ctx context.Context, sync *pkg.Sync) error {
+				return sync.Milestone(ctx, milestone)
+			},
+		}
+	default:
+		// Other events are not relevant
+		w.WriteHeader(http.StatusOK)
+		return
+	}
+
+	queue, ok := wr.queues[strings.ToLower(repo.GetFullName())]
+	if !ok {
+		// Not a synchronized repository
+		w.WriteHeader(http.StatusOK)
+		return
+	}
+	select {
+	case queue <- job:
+		w.WriteHeader(http.StatusAccepted)
+	default:
+		http.Error(w, "too many pending events", http.StatusServiceUnavailable)
+	}
+}
+
+func issueWebhookJob(issue *gh.Issue) webhookJob {
+	number := issue.GetNumber()
+	return webhookJob{
+		description: fmt.Sprintf("issue #%d", number),
+		run: func(ctx context.Context, sync *pkg.Sync) error {
+			return sync.Issue(ctx, number)
+		},
+	}
+}
+
+// validateWebhookSignature checks the HMAC SHA-256 signature of a webhook payload
+func validateWebhookSignature(signature string, payload, secret []byte) bool {
+	const prefix = "sha256="
+	if !strings.HasPrefix(signature, prefix) {
+		return false
+	}
+	actual, err := hex.DecodeString(strings.TrimPrefix(signature, prefix))
+	if err != nil {
+		return false
+	}
+	mac := hmac.New(sha256.New, secret)
+	mac.Write(payload)
+	return hmac.Equal(actual, mac.Sum(nil))
+}
@@ -0,0 +1,91 @@
+package cmd
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func signWebhookPayload(payload, secret string) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(payload))
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+func TestValidateWebhookSignature(t *testing.T) {
+	payload := `{"zen":"Keep it logically awesome."}`
+	tests := []struct {
+		name      string
+		signature string
+		expected  bool
+	}{
+		{"Valid", signWebhookPayload(payload, "secret"), true},
+		{"WrongSecret", signWebhookPayload(payload, "other"), false},
+		{"Missing", "", false},
+		{"SHA1", "sha1=" + strings.TrimPrefix(signWebhookPayload(payload, "secret"), "sha256="), false},
+		{"NotHex", "sha256=zz", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := validateWebhookSignature(tt.signature, []byte(payload), []byte("secret")); got != tt.expected {
+				t.Errorf("validateWebhookSignature() = %v, expecting %v", got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestWebhookReceiver(t *testing.T) {
+	const (
+		issuePayload     = `{"action":"opened","issue":{"number":1},"repository":{"full_name":"Owner/Repo"}}`
+		prCommentPayload = `{"action":"created","issue":{"number":2,"pull_request":{"url":"https://api.github.com/repos/owner/repo/pulls/2"}},"repository":{"full_name":"owner/repo"}}`
+		otherRepoPayload = `{"action":"opened","issue":{"number":1},"repository":{"full_name":"owner/other"}}`
+	)
+	tests := []struct {
+		name           string
+		method         string
+		event          string
+		payload        string
+		signature      string
+		fullQueue      bool
+		expectedStatus int
+		expectedJobs   int
+	}{
+		{"Issue", http.MethodPost, "issues", issuePayload, signWebhookPayload(issuePayload, "secret"), false, http.StatusAccepted, 1},
+		{"BadSignature", http.MethodPost, "issues", issuePayload, signWebhookPayload(issuePayload, "other"), false, http.StatusUnauthorized, 0},
+		{"MissingSignature", http.MethodPost, "issues", issuePayload, "", false, http.StatusUnauthorized, 0},
+		{"Ping", http.MethodPost, "ping", `{}`, signWebhookPayload(`{}`, "secret"), false, http.StatusOK, 0},
+		{"UnknownRepository", http.MethodPost, "issues", otherRepoPayload, signWebhookPayload(otherRepoPayload, "secret"), false, http.StatusOK, 0},
+		{"PullRequestComment", http.MethodPost, "issue_comment", prCommentPayload, signWebhookPayload(prCommentPayload, "secret"), false, http.StatusOK, 0},
+		{"FullQueue", http.MethodPost, "issues", issuePayload, signWebhookPayload(issuePayload, "secret"), true, http.StatusServiceUnavailable, 1},
+		{"MethodNotAllowed", http.MethodGet, "issues", "", "", false, http.StatusMethodNotAllowed, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			queue := make(chan webhookJob, 1)
+			if tt.fullQueue {
+				queue <- webhookJob{description: "pending"}
+			}
+			receiver := &webhookReceiver{
+				secret: []byte("secret"),
+				queues: map[string]chan webhookJob{"owner/repo": queue},
+			}
+			req := httptest.NewRequest(tt.method, "/", strings.NewReader(tt.payload))
+			req.Header.Set(webhookEventHeader, tt.event)
+			if tt.signature != "" {
+				req.Header.Set(webhookSignatureHeader, tt.signature)
+			}
+			w := httptest.NewRecorder()
+			receiver.ServeHTTP(w, req)
+			if w.Code != tt.expectedStatus {
+				t.Errorf("unexpected status %d, expecting %d", w.Code, tt.expectedStatus)
+			}
+			if len(queue) != tt.expectedJobs {
+				t.Errorf("unexpected number of queued jobs %d, expecting %d", len(queue), tt.expectedJobs)
+			}
+		})
+	}
+}
@@ -5,20 +5,18 @@ go 1.13
 require (
 	github.com/andygrunwald/go-jira v1.10.0
 	github.com/fatih/structs v1.1.0 // indirect
-	github.com/golang/protobuf v1.3.2 // indirect
 	github.com/google/go-github/v24 v24.0.1
 	github.com/magiconair/properties v1.8.1 // indirect
 	github.com/mitchellh/go-homedir v1.1.0
 	github.com/pelletier/go-toml v1.4.0 // indirect
-	github.com/pkg/errors v0.8.1
+	github.com/pkg/errors v0.9.1
+	github.com/prometheus/client_golang v1.12.2
+	github.com/sirupsen/logrus v1.8.1
 	github.com/spf13/afero v1.2.2 // indirect
 	github.com/spf13/cobra v0.0.5
 	github.com/spf13/jwalterweatherman v1.1.0 // indirect
 	github.com/spf13/pflag v1.0.5 // indirect
 	github.com/spf13/viper v1.4.0
 	github.com/trivago/tgo v1.0.7 // indirect
-	golang.org/x/net v0.0.0-20190921015927-1a5e07d1ff72 // indirect
-	golang.org/x/oauth2 v0.0.0-20190604053449-0f29369cfe45
-	golang.org/x/sys v0.0.0-20190922100055-0a153f010e69 // indirect
-	google.golang.org/appengine v1.6.3 // indirect
+	golang.org/x/oauth2 v0.0.0-20210514164344-f6687ab2804c
 )
@@ -0,0 +1,143 @@
+package pkg
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"io/ioutil"
+	"mime"
+	"net/http"
+	"net/url"
+	"path"
+	"regexp"
+
+	jiralib "github.com/andygrunwald/go-jira"
+	gh "github.com/google/go-github/v24/github"
+	"github.com/pkg/errors"
+
+	"github.com/ystia/zenhub-jira-sync/pkg/markup"
+)
+
+// maxAttachmentSize is the size of the largest GitHub attachment mirrored to Jira
+const maxAttachmentSize = 25 * 1024 * 1024
+
+// githubAttachmentURLRE matches URLs of files uploaded to GitHub issues and comments
+var githubAttachmentURLRE = regexp.MustCompile(`^https://(?:(?:private-)?user-images\.githubusercontent\.com/|github\.com/(?:user-attachments/(?:assets|files)/|[^/]+/[^/]+/(?:files|assets)/))`)
+
+var unsafeFilenameCharsRE = regexp.MustCompile(`[^A-Za-z0-9._-]+`)
+
+// mirrorAttachments uploads files attached to the given GitHub issue body and comments to the Jira issue.
+//
+// Attachments are named after a hash of their content, so that files already attached to the Jira issue are not
+// uploaded again. It returns the names of the Jira attachments indexed by GitHub URL, files that could not be
+// downloaded are not part of the result and are still referenced using their GitHub URL.
+func (s *Sync) mirrorAttachments(ctx context.Context, jiraIssue *jiralib.Issue, body string, ghComments []*gh.IssueComment) (map[string]string, error) {
+	if s.AttachmentsFetcher == nil {
+		return nil, nil
+	}
+	var urls []string
+	for _, link := range markup.Links(body) {
+		if githubAttachmentURLRE.MatchString(link) {
+			urls = append(urls, link)
+		}
+	}
+	for _, ghc := range ghComments {
+		for _, link := range markup.Links(ghc.GetBody()) {
+			if githubAttachmentURLRE.MatchString(link) {
+				urls = append(urls, link)
+			}
+		}
+	}
+	if len(urls) == 0 {
+		return nil, nil
+	}
+
+	existingAttachments := make(map[string]bool)
+	if jiraIssue.Fields != nil {
+		for _, a := range jiraIssue.Fields.Attachments {
+			existingAttachments[a.Filename] = true
+		}
+	}
+	attachments := make(map[string]string, len(urls))
+	for _, u := range urls {
+		if filename, ok := s.getCachedAttachment(jiraIssue.Key, u); ok {
+			attachments[u] = filename
+			continue
+		}
+		content, filename, err := s.fetchAttachment(ctx, u)
+		if err != nil {
+			// Keep referencing the file on GitHub rather than failing the whole issue
+			s.logger().WithField("jira_key", jiraIssue.Key).Warnf("Failed to download attachment %s: %v", u, err)
+			continue
+		}
+		if !existingAttachments[filename] {
+			_, err = s.JiraClient.AddAttachment(jiraIssue.Key, filename, bytes.NewReader(content))
+			if err != nil {
+				return nil, err
+			}
+			existingAttachments[filename] = true
+		}
+		s.cacheAttachment(jiraIssue.Key, u, filename)
+		attachments[u] = filename
+	}
+	return attachments, nil
+}
+
+// fetchAttachment downloads a file and returns its content and the name of the Jira attachment
+func (s *Sync) fetchAttachment(ctx context.Context, fileURL string) ([]byte, string, error) {
+	req, err := http.NewRequest(http.MethodGet, fileURL, nil)
+	if err != nil {
+		return nil, "", errors.Wrapf(err, "invalid attachment URL %q", fileURL)
+	}
+	resp, err := s.AttachmentsFetcher.Do(req.WithContext(ctx))
+	if err != nil {
+		return nil, "", errors.Wrapf(err, "failed to download attachment %q", fileURL)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, "", errors.Errorf("failed to download attachment %q: unexpected status %q", fileURL, resp.Status)
+	}
+	content, err := ioutil.ReadAll(io.LimitReader(resp.Body, maxAttachmentSize+1))
+	if err != nil {
+		return nil, "", errors.Wrapf(err, "failed to download attachment %q", fileURL)
+	}
+	if len(content) > maxAttachmentSize {
+		return nil, "", errors.Errorf("attachment %q is larger than %d bytes", fileURL, maxAttachmentSize)
+	}
+	return content, attachmentFilename(fileURL, resp.Header.Get("Content-Type"), content), nil
+}
+
+// attachmentFilename builds the name of a Jira attachment from a hash of its content and its original name
+func attachmentFilename(fileURL, contentType string, content []byte) string {
+	h := sha256.Sum256(content)
+	name := "attachment"
+	if u, err := url.Parse(fileURL); err == nil && path.Base(u.Path) != "/" && path.Base(u.Path) != "." {
+		name = unsafeFilenameCharsRE.ReplaceAllString(path.Base(u.Path), "_")
+	}
+	if path.Ext(name) == "" {
+		if mediaType, _, err := mime.ParseMediaType(contentType); err == nil {
+			if exts, _ := mime.ExtensionsByType(mediaType); len(exts) > 0 {
+				name += exts[0]
+			}
+		}
+	}
+	return hex.EncodeToString(h[:8]) + "-" + name
+}
+
+func (s *Sync) getCachedAttachment(issueKey, fileURL string) (string, bool) {
+	s.attachmentsLock.Lock()
+	defer s.attachmentsLock.Unlock()
+	filename, ok := s.attachments[issueKey+" "+fileURL]
+	return filename, ok
+}
+
+func (s *Sync) cacheAttachment(issueKey, fileURL, filename string) {
+	s.attachmentsLock.Lock()
+	defer s.attachmentsLock.Unlock()
+	if s.attachments == nil {
+		s.attachments = make(map[string]string)
+	}
+	s.attachments[issueKey+" "+fileURL] = filename
+}
@@ -0,0 +1,32 @@
+package pkg
+
+import (
+	"testing"
+)
+
+func TestAttachmentFilename(t *testing.T) {
+	content := []byte("content")
+	tests := []struct {
+		name        string
+		url         string
+		contentType string
+		expected    string
+	}{
+		{"UserImage", "https://user-images.githubusercontent.com/1234/5678-abcd.png", "image/png", "ed7002b439e9ac84-5678-abcd.png"},
+		{"UserAttachment", "https://github.com/user-attachments/assets/0a1b-2c3d", "image/png; charset=binary", "ed7002b439e9ac84-0a1b-2c3d.png"},
+		{"UnsafeCharacters", "https://github.com/owner/repo/files/42/my%20logs.zip", "application/zip", "ed7002b439e9ac84-my_logs.zip"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if !githubAttachmentURLRE.MatchString(tt.url) {
+				t.Errorf("expecting %q to be recognized as a GitHub attachment", tt.url)
+			}
+			if got := attachmentFilename(tt.url, tt.contentType, content); got != tt.expected {
+				t.Errorf("attachmentFilename() = %q, expecting %q", got, tt.expected)
+			}
+		})
+	}
+	if githubAttachmentURLRE.MatchString("https://github.com/owner/repo/issues/1") {
+		t.Error("issues URLs should not be recognized as GitHub attachments")
+	}
+}
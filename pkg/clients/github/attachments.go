@@ -0,0 +1,40 @@
+package github
+
+import (
+	"net/http"
+	"strings"
+)
+
+// AttachmentsTransport is an http.RoundTripper downloading files attached to GitHub issues.
+//
+// Requests to GitHub hosts go through the Authenticated transport, so that attachments of private repositories
+// are accessible, while other requests, such as redirections to storage services, go through Base in order to
+// never send the GitHub token to third parties.
+type AttachmentsTransport struct {
+	// Authenticated is the transport authenticating requests to GitHub hosts, such as an oauth2.Transport
+	Authenticated http.RoundTripper
+	// Base is used for other hosts, http.DefaultTransport is used if nil
+	Base http.RoundTripper
+}
+
+// RoundTrip executes a single HTTP transaction using the transport matching the request host
+func (t *AttachmentsTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.URL.Scheme == "https" && isGitHubHost(req.URL.Hostname()) {
+		return t.Authenticated.RoundTrip(req)
+	}
+	if t.Base != nil {
+		return t.Base.RoundTrip(req)
+	}
+	return http.DefaultTransport.RoundTrip(req)
+}
+
+// isGitHubHost checks if the given host belongs to github.com or githubusercontent.com
+func isGitHubHost(host string) bool {
+	host = strings.ToLower(host)
+	for _, domain := range []string{"github.com", "githubusercontent.com"} {
+		if host == domain || strings.HasSuffix(host, "."+domain) {
+			return true
+		}
+	}
+	return false
+}
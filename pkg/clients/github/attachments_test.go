@@ -0,0 +1,36 @@
+package github
+
+import (
+	"net/http"
+	"testing"
+)
+
+type hostRecorder struct {
+	hosts []string
+}
+
+func (r *hostRecorder) RoundTrip(req *http.Request) (*http.Response, error) {
+	r.hosts = append(r.hosts, req.URL.Host)
+	return &http.Response{StatusCode: http.StatusOK, Body: http.NoBody, Request: req}, nil
+}
+
+func TestAttachmentsTransport(t *testing.T) {
+	authenticated, base := new(hostRecorder), new(hostRecorder)
+	transport := &AttachmentsTransport{Authenticated: authenticated, Base: base}
+	for _, u := range []string{
+		"https://github.com/user-attachments/assets/1",
+		"https://private-user-images.githubusercontent.com/1/2.png",
+		"https://github-production-user-asset-6210df.s3.amazonaws.com/1/2.png",
+		"https://evilgithub.com/file",
+		"http://github.com/insecure",
+	} {
+		req, _ := http.NewRequest(http.MethodGet, u, nil)
+		_, err := transport.RoundTrip(req)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+	if len(authenticated.hosts) != 2 || len(base.hosts) != 3 {
+		t.Errorf("unexpected authenticated hosts %v and unauthenticated hosts %v", authenticated.hosts, base.hosts)
+	}
+}
@@ -26,9 +26,6 @@ func (c *Client) GetIssueComments(ctx context.Context, issueNumber int) ([]*gh.I
 		}
 		page = resp.NextPage
 	}
-
-	return nil, nil
-
 }
 
 func (c *Client) listIssueComments(ctx context.Context, issueNumber, page int) ([]*gh.IssueComment, *gh.Response, error) {
@@ -39,3 +36,23 @@ func (c *Client) listIssueComments(ctx context.Context, issueNumber, page int) (
 	})
 	return comments, resp, errors.Wrapf(err, "failed to list comments for issue #%d", issueNumber)
 }
+
+// CreateIssueComment creates a new comment on the specified issue.
+//
+// GitHub API docs: https://developer.github.com/v3/issues/comments/#create-a-comment
+func (c *Client) CreateIssueComment(ctx context.Context, issueNumber int, body string) (*gh.IssueComment, error) {
+	comment, _, err := c.GHClient.Issues.CreateComment(ctx, c.Owner, c.Repo, issueNumber, &gh.IssueComment{
+		Body: &body,
+	})
+	return comment, errors.Wrapf(err, "failed to create comment for issue #%d", issueNumber)
+}
+
+// EditIssueComment updates an issue comment.
+//
+// GitHub API docs: https://developer.github.com/v3/issues/comments/#edit-a-comment
+func (c *Client) EditIssueComment(ctx context.Context, commentID int64, body string) (*gh.IssueComment, error) {
+	comment, _, err := c.GHClient.Issues.EditComment(ctx, c.Owner, c.Repo, commentID, &gh.IssueComment{
+		Body: &body,
+	})
+	return comment, errors.Wrapf(err, "failed to update comment %d", commentID)
+}
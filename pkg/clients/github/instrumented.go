@@ -0,0 +1,109 @@
+package github
+
+import (
+	"context"
+	"time"
+
+	gh "github.com/google/go-github/v24/github"
+)
+
+// InstrumentedClient wraps an API and reports each call with its duration and error to the OnCall function.
+type InstrumentedClient struct {
+	API
+	// OnCall is called after each API call with the API method name, the call duration and the returned error
+	OnCall func(method string, duration time.Duration, err error)
+}
+
+func (c *InstrumentedClient) onCall(method string, start time.Time, err error) {
+	if c.OnCall != nil {
+		c.OnCall(method, time.Since(start), err)
+	}
+}
+
+// ListMilestones reports the call
+func (c *InstrumentedClient) ListMilestones(ctx context.Context) ([]*gh.Milestone, error) {
+	start := time.Now()
+	result, err := c.API.ListMilestones(ctx)
+	c.onCall("ListMilestones", start, err)
+	return result, err
+}
+
+// GetRepository reports the call
+func (c *InstrumentedClient) GetRepository(ctx context.Context) (*gh.Repository, error) {
+	start := time.Now()
+	result, err := c.API.GetRepository(ctx)
+	c.onCall("GetRepository", start, err)
+	return result, err
+}
+
+// GetRepositoryByID reports the call
+func (c *InstrumentedClient) GetRepositoryByID(ctx context.Context, repoID int64) (*gh.Repository, error) {
+	start := time.Now()
+	result, err := c.API.GetRepositoryByID(ctx, repoID)
+	c.onCall("GetRepositoryByID", start, err)
+	return result, err
+}
+
+// GetIssue reports the call
+func (c *InstrumentedClient) GetIssue(ctx context.Context, number int) (*gh.Issue, error) {
+	start := time.Now()
+	result, err := c.API.GetIssue(ctx, number)
+	c.onCall("GetIssue", start, err)
+	return result, err
+}
+
+// ListIssues reports the call
+func (c *InstrumentedClient) ListIssues(ctx context.Context, opts *gh.IssueListByRepoOptions) ([]*gh.Issue, error) {
+	start := time.Now()
+	result, err := c.API.ListIssues(ctx, opts)
+	c.onCall("ListIssues", start, err)
+	return result, err
+}
+
+// ListIssueEvents reports the call
+func (c *InstrumentedClient) ListIssueEvents(ctx context.Context, number int) ([]*gh.IssueEvent, error) {
+	start := time.Now()
+	result, err := c.API.ListIssueEvents(ctx, number)
+	c.onCall("ListIssueEvents", start, err)
+	return result, err
+}
+
+// GetIssueFromRepoID reports the call
+func (c *InstrumentedClient) GetIssueFromRepoID(ctx context.Context, repoID int64, number int) (*gh.Issue, error) {
+	start := time.Now()
+	result, err := c.API.GetIssueFromRepoID(ctx, repoID, number)
+	c.onCall("GetIssueFromRepoID", start, err)
+	return result, err
+}
+
+// GetIssueComments reports the call
+func (c *InstrumentedClient) GetIssueComments(ctx context.Context, issueNumber int) ([]*gh.IssueComment, error) {
+	start := time.Now()
+	result, err := c.API.GetIssueComments(ctx, issueNumber)
+	c.onCall("GetIssueComments", start, err)
+	return result, err
+}
+
+// CreateIssueComment reports the call
+func (c *InstrumentedClient) CreateIssueComment(ctx context.Context, issueNumber int, body string) (*gh.IssueComment, error) {
+	start := time.Now()
+	result, err := c.API.CreateIssueComment(ctx, issueNumber, body)
+	c.onCall("CreateIssueComment", start, err)
+	return result, err
+}
+
+// EditIssueComment reports the call
+func (c *InstrumentedClient) EditIssueComment(ctx context.Context, commentID int64, body string) (*gh.IssueComment, error) {
+	start := time.Now()
+	result, err := c.API.EditIssueComment(ctx, commentID, body)
+	c.onCall("EditIssueComment", start, err)
+	return result, err
+}
+
+// GetUser reports the call
+func (c *InstrumentedClient) GetUser(ctx context.Context, login string) (*gh.User, error) {
+	start := time.Now()
+	result, err := c.API.GetUser(ctx, login)
+	c.onCall("GetUser", start, err)
+	return result, err
+}
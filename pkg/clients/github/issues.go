@@ -54,3 +54,22 @@ func (c *Client) listIssues(ctx context.Context, opts *gh.IssueListByRepoOptions
 	issues, resp, err := c.GHClient.Issues.ListByRepo(ctx, c.Owner, c.Repo, opts)
 	return issues, resp, errors.Wrapf(err, "failed to list issues for repository %s/%s", c.Owner, c.Repo)
 }
+
+// ListIssueEvents lists events for the specified issue.
+//
+// GitHub API docs: https://developer.github.com/v3/issues/events/#list-events-for-an-issue
+func (c *Client) ListIssueEvents(ctx context.Context, number int) ([]*gh.IssueEvent, error) {
+	events := make([]*gh.IssueEvent, 0)
+	opts := &gh.ListOptions{PerPage: 100}
+	for {
+		e, resp, err := c.GHClient.Issues.ListIssueEvents(ctx, c.Owner, c.Repo, number, opts)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to list events of issue %s/%s#%d", c.Owner, c.Repo, number)
+		}
+		events = append(events, e...)
+		if resp.NextPage == 0 {
+			return events, nil
+		}
+		opts.Page = resp.NextPage
+	}
+}
@@ -0,0 +1,51 @@
+package github
+
+import (
+	"context"
+	"fmt"
+
+	gh "github.com/google/go-github/v24/github"
+)
+
+// RecordingClient wraps an API and reports write operations to the OnWrite function.
+//
+// In dry-run mode write operations are only reported and not applied using the wrapped API, otherwise they are
+// reported once successfully applied. Read operations always go through the wrapped API.
+type RecordingClient struct {
+	API
+	DryRun bool
+	// OnWrite is called for each write operation with the operation name, its target and its content
+	OnWrite func(operation, target, content string)
+}
+
+func (c *RecordingClient) onWrite(operation, target, content string) {
+	if c.OnWrite != nil {
+		c.OnWrite(operation, target, content)
+	}
+}
+
+// CreateIssueComment reports a comment creation
+func (c *RecordingClient) CreateIssueComment(ctx context.Context, issueNumber int, body string) (*gh.IssueComment, error) {
+	if !c.DryRun {
+		comment, err := c.API.CreateIssueComment(ctx, issueNumber, body)
+		if err == nil {
+			c.onWrite("create", fmt.Sprintf("#%d", issueNumber), body)
+		}
+		return comment, err
+	}
+	c.onWrite("create", fmt.Sprintf("#%d", issueNumber), body)
+	return &gh.IssueComment{Body: &body}, nil
+}
+
+// EditIssueComment reports a comment update
+func (c *RecordingClient) EditIssueComment(ctx context.Context, commentID int64, body string) (*gh.IssueComment, error) {
+	if !c.DryRun {
+		comment, err := c.API.EditIssueComment(ctx, commentID, body)
+		if err == nil {
+			c.onWrite("update", fmt.Sprintf("comment %d", commentID), body)
+		}
+		return comment, err
+	}
+	c.onWrite("update", fmt.Sprintf("comment %d", commentID), body)
+	return &gh.IssueComment{ID: &commentID, Body: &body}, nil
+}
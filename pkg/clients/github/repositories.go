@@ -15,3 +15,11 @@ func (c *Client) GetRepository(ctx context.Context) (*gh.Repository, error) {
 	repo, _, err := c.GHClient.Repositories.Get(ctx, c.Owner, c.Repo)
 	return repo, errors.Wrapf(err, "failed to get %s/%s github repository", c.Owner, c.Repo)
 }
+
+// GetRepositoryByID fetches a repository from its id.
+//
+// GitHub API docs: https://developer.github.com/v3/repos/#get
+func (c *Client) GetRepositoryByID(ctx context.Context, repoID int64) (*gh.Repository, error) {
+	repo, _, err := c.GHClient.Repositories.GetByID(ctx, repoID)
+	return repo, errors.Wrapf(err, "failed to get info for repository with id %d", repoID)
+}
@@ -17,6 +17,10 @@ type API interface {
 	//
 	// GitHub API docs: https://developer.github.com/v3/repos/#get
 	GetRepository(ctx context.Context) (*gh.Repository, error)
+	// GetRepositoryByID fetches a repository from its id.
+	//
+	// GitHub API docs: https://developer.github.com/v3/repos/#get
+	GetRepositoryByID(ctx context.Context, repoID int64) (*gh.Repository, error)
 
 	// GetIssue returns a single issue.
 	//
@@ -28,6 +32,11 @@ type API interface {
 	// GitHub API docs: https://developer.github.com/v3/issues/#list-issues-for-a-repository
 	ListIssues(ctx context.Context, opts *gh.IssueListByRepoOptions) ([]*gh.Issue, error)
 
+	// ListIssueEvents lists events for the specified issue.
+	//
+	// GitHub API docs: https://developer.github.com/v3/issues/events/#list-events-for-an-issue
+	ListIssueEvents(ctx context.Context, number int) ([]*gh.IssueEvent, error)
+
 	// GetIssueFromRepoID returns a single issue from repository id.
 	//
 	// GitHub API docs: https://developer.github.com/v3/issues/#get-a-single-issue
@@ -38,6 +47,21 @@ type API interface {
 	//
 	// GitHub API docs: https://developer.github.com/v3/issues/comments/#list-comments-on-an-issue
 	GetIssueComments(ctx context.Context, issueNumber int) ([]*gh.IssueComment, error)
+
+	// CreateIssueComment creates a new comment on the specified issue.
+	//
+	// GitHub API docs: https://developer.github.com/v3/issues/comments/#create-a-comment
+	CreateIssueComment(ctx context.Context, issueNumber int, body string) (*gh.IssueComment, error)
+
+	// EditIssueComment updates an issue comment.
+	//
+	// GitHub API docs: https://developer.github.com/v3/issues/comments/#edit-a-comment
+	EditIssueComment(ctx context.Context, commentID int64, body string) (*gh.IssueComment, error)
+
+	// GetUser fetches a user.
+	//
+	// GitHub API docs: https://developer.github.com/v3/users/#get-a-single-user
+	GetUser(ctx context.Context, login string) (*gh.User, error)
 }
 
 // Client manages communication with the GitHub API.
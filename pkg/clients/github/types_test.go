@@ -6,4 +6,6 @@ import (
 
 func TestAPIImplementation(t *testing.T) {
 	var _ API = (*Client)(nil)
+	var _ API = (*RecordingClient)(nil)
+	var _ API = (*InstrumentedClient)(nil)
 }
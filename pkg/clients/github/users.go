@@ -0,0 +1,17 @@
+package github
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+
+	gh "github.com/google/go-github/v24/github"
+)
+
+// GetUser fetches a user.
+//
+// GitHub API docs: https://developer.github.com/v3/users/#get-a-single-user
+func (c *Client) GetUser(ctx context.Context, login string) (*gh.User, error) {
+	user, _, err := c.GHClient.Users.Get(ctx, login)
+	return user, errors.Wrapf(err, "failed to get github user %q", login)
+}
@@ -0,0 +1,22 @@
+package jira
+
+import (
+	"io"
+
+	jiralib "github.com/andygrunwald/go-jira"
+	"github.com/pkg/errors"
+)
+
+// AddAttachment uploads the given content as an attachment named filename to an issue.
+//
+// JIRA API docs: https://docs.atlassian.com/jira/REST/latest/#api/2/issue/{issueIdOrKey}/attachments-addAttachment
+func (c *Client) AddAttachment(issueKeyOrID, filename string, content io.Reader) (*jiralib.Attachment, error) {
+	attachments, _, err := c.JiraClient.Issue.PostAttachment(issueKeyOrID, content, filename)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to attach file %q to issue %q", filename, issueKeyOrID)
+	}
+	if attachments == nil || len(*attachments) == 0 {
+		return nil, errors.Errorf("failed to attach file %q to issue %q: no attachment returned", filename, issueKeyOrID)
+	}
+	return &(*attachments)[0], nil
+}
@@ -6,15 +6,30 @@ import (
 
 // Client manages communication with the Jira API.
 type Client struct {
-	JiraClient      *jiralib.Client
-	ProjectKey      string
-	BoardID         int
-	customFieldsIDs map[string]string
+	JiraClient *jiralib.Client
+	ProjectKey string
+	BoardID    int
+	// UseUsernames identifies users by their username instead of their account ID, as required by Jira Server
+	UseUsernames bool
+	// EstimableIssueTypes are names of issue types supporting estimation,
+	// if empty they are discovered from the board configuration
+	EstimableIssueTypes []string
+	// CustomFields overrides fields used by the synchronization, they are indexed by their keys (see CustomFieldsKeys)
+	// and are either a field name or a field ID such as customfield_10010
+	CustomFields        map[string]string
+	customFieldsIDs     map[string]string
+	fields              []jiralib.Field
+	estimableIssueTypes map[string]bool
 }
 
 // Init initialize the client by getting some instance specific IDs
 //
 // Call to this function is required before using it to synchronize ZenHub and Jira
 func (c *Client) Init() error {
-	return c.initCustomFields()
+	err := c.initCustomFields()
+	if err != nil {
+		return err
+	}
+	c.initEstimableIssueTypes()
+	return nil
 }
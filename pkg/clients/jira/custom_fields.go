@@ -1,6 +1,14 @@
 package jira
 
-import "github.com/pkg/errors"
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	jiralib "github.com/andygrunwald/go-jira"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+)
 
 const (
 	CFNameGitHubID            = "GitHub ID"
@@ -13,39 +21,116 @@ const (
 	CFNameEpicLink            = "Epic Link"
 	CFNameSprint              = "Sprint"
 	CFNameStatus              = "Status"
+	CFNameRank                = "Rank"
 )
 
-func (c *Client) initCustomFields() error {
-	c.customFieldsIDs = map[string]string{
-		CFNameGitHubID:            "",
-		CFNameGitHubNumber:        "",
-		CFNameGitHubLabels:        "",
-		CFNameGitHubStatus:        "",
-		CFNameGitHubReporter:      "",
-		CFNameGitHubLastIssueSync: "",
-		CFNameEpicName:            "",
-		CFNameEpicLink:            "",
-		CFNameSprint:              "",
-		CFNameStatus:              "",
+// customField is a field used by the synchronization
+type customField struct {
+	// key identifies the field in the CustomFields configuration of the client
+	key string
+	// name is the default name of the field, it is also used to identify the field in the synchronization
+	name string
+	// optional fields are skipped if they do not exist
+	optional bool
+	// fieldType is the type of fields created by Setup, fields without type are provided by Jira and are not created
+	fieldType string
+}
+
+// Types of fields created by Setup
+const (
+	fieldTypeNumber   = "number"
+	fieldTypeText     = "text"
+	fieldTypeDateTime = "datetime"
+)
+
+// registeredCustomFields is the list of fields that are used by the synchronization
+var registeredCustomFields = []customField{
+	{key: "github_id", name: CFNameGitHubID, fieldType: fieldTypeNumber},
+	{key: "github_number", name: CFNameGitHubNumber, optional: true, fieldType: fieldTypeNumber},
+	{key: "github_labels", name: CFNameGitHubLabels, optional: true, fieldType: fieldTypeText},
+	{key: "github_status", name: CFNameGitHubStatus, optional: true, fieldType: fieldTypeText},
+	{key: "github_reporter", name: CFNameGitHubReporter, optional: true, fieldType: fieldTypeText},
+	{key: "last_issue_sync_update", name: CFNameGitHubLastIssueSync, optional: true, fieldType: fieldTypeDateTime},
+	{key: "epic_name", name: CFNameEpicName},
+	{key: "epic_link", name: CFNameEpicLink},
+	{key: "sprint", name: CFNameSprint},
+	{key: "status", name: CFNameStatus},
+	// Rank is only required to rank issues
+	{key: "rank", name: CFNameRank, optional: true},
+}
+
+// CustomFieldsKeys returns the keys of fields that may be configured using the CustomFields of the client
+func CustomFieldsKeys() []string {
+	keys := make([]string, len(registeredCustomFields))
+	for i, cf := range registeredCustomFields {
+		keys[i] = cf.key
 	}
+	return keys
+}
+
+func (c *Client) initCustomFields() error {
 	jiraFields, _, err := c.JiraClient.Field.GetList()
 	if err != nil {
 		return errors.Wrap(err, "Failed to get Jira custom fields")
 	}
+	c.fields = jiraFields
+	var warnings []string
+	c.customFieldsIDs, warnings, err = resolveCustomFields(jiraFields, c.CustomFields)
+	for _, warning := range warnings {
+		logrus.Warnf("%s", warning)
+	}
+	return err
+}
 
+// resolveCustomFields returns IDs of registered fields indexed by their names.
+//
+// Fields are identified by their default name unless overridden in customFields by a field name or ID indexed by
+// the field key. Missing optional fields are skipped with a warning, all other issues are reported in the returned error.
+func resolveCustomFields(jiraFields []jiralib.Field, customFields map[string]string) (map[string]string, []string, error) {
+	fieldsIDs := make(map[string]bool, len(jiraFields))
+	fieldsByName := make(map[string][]string, len(jiraFields))
 	for _, field := range jiraFields {
-		// Get all fields
-		c.customFieldsIDs[field.Name] = field.ID
+		fieldsIDs[field.ID] = true
+		fieldsByName[field.Name] = append(fieldsByName[field.Name], field.ID)
 	}
 
-	// Check that pre-registered fields were present
-	for cfName, cfID := range c.customFieldsIDs {
-		if cfID == "" {
-			return errors.Errorf("failed to get ID of field %q in JIRA make sure it have been properly created", cfName)
+	var warnings, problems []string
+	knownKeys := make(map[string]bool, len(registeredCustomFields))
+	ids := make(map[string]string, len(registeredCustomFields))
+	for _, cf := range registeredCustomFields {
+		knownKeys[cf.key] = true
+		ref, configured := customFields[cf.key]
+		if !configured || ref == "" {
+			ref = cf.name
+		}
+		if fieldsIDs[ref] {
+			ids[cf.name] = ref
+			continue
+		}
+		matches := fieldsByName[ref]
+		switch {
+		case len(matches) == 1:
+			ids[cf.name] = matches[0]
+		case len(matches) > 1:
+			sort.Strings(matches)
+			problems = append(problems, fmt.Sprintf("field %q is ambiguous as %d fields are named %q (%s), set custom_fields.%s to one of these IDs",
+				cf.key, len(matches), ref, strings.Join(matches, ", "), cf.key))
+		case cf.optional && !configured:
+			warnings = append(warnings, fmt.Sprintf("optional field %q was not found in Jira using name %q, it will not be synchronized", cf.key, ref))
+		default:
+			problems = append(problems, fmt.Sprintf("field %q was not found in Jira using %q, make sure it has been properly created", cf.key, ref))
 		}
 	}
-
-	return nil
+	for key := range customFields {
+		if !knownKeys[key] {
+			problems = append(problems, fmt.Sprintf("unknown field %q, expecting one of %s", key, strings.Join(CustomFieldsKeys(), ", ")))
+		}
+	}
+	if len(problems) > 0 {
+		sort.Strings(problems)
+		return ids, warnings, errors.Errorf("invalid Jira custom fields: %s", strings.Join(problems, "; "))
+	}
+	return ids, warnings, nil
 }
 
 // GetCustomFieldID returns a custom field ID based on its name. If not found an empty string is returned.
@@ -53,14 +138,44 @@ func (c *Client) GetCustomFieldID(name string) string {
 	return c.customFieldsIDs[name]
 }
 
-// IsIssueTypeEstimable checks if issue type support estimation through Story Points custom field
-//
-// Currently only "User story" support estimate
-func IsIssueTypeEstimable(issueTypeName string) bool {
-	switch issueTypeName {
-	case "User story":
-		return true
+// FindField returns a Jira field identified by its ID or its name, an error is returned if it does not exist or if
+// several fields have this name
+func (c *Client) FindField(ref string) (*jiralib.Field, error) {
+	var matches []*jiralib.Field
+	for i := range c.fields {
+		if c.fields[i].ID == ref {
+			return &c.fields[i], nil
+		}
+		if c.fields[i].Name == ref {
+			matches = append(matches, &c.fields[i])
+		}
+	}
+	switch len(matches) {
+	case 0:
+		return nil, errors.Errorf("field %q was not found in Jira", ref)
+	case 1:
+		return matches[0], nil
 	default:
-		return false
+		ids := make([]string, len(matches))
+		for i, field := range matches {
+			ids[i] = field.ID
+		}
+		sort.Strings(ids)
+		return nil, errors.Errorf("field %q is ambiguous as %d fields have this name (%s), use one of these IDs", ref, len(matches), strings.Join(ids, ", "))
+	}
+}
+
+// jqlField returns a reference to a field usable in JQL queries
+func jqlField(id string) string {
+	if strings.HasPrefix(id, "customfield_") {
+		return fmt.Sprintf("cf[%s]", strings.TrimPrefix(id, "customfield_"))
+	}
+	return id
+}
+
+// setCustomField sets the value of a field in the given unknown fields of an issue, skipped fields are ignored
+func (c *Client) setCustomField(unknowns map[string]interface{}, name string, value interface{}) {
+	if id := c.GetCustomFieldID(name); id != "" {
+		unknowns[id] = value
 	}
 }
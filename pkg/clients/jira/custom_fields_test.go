@@ -0,0 +1,57 @@
+package jira
+
+import (
+	"strings"
+	"testing"
+
+	jiralib "github.com/andygrunwald/go-jira"
+)
+
+func TestResolveCustomFields(t *testing.T) {
+	jiraFields := []jiralib.Field{
+		{ID: "customfield_10001", Name: CFNameGitHubID},
+		{ID: "customfield_10002", Name: "GH Number"},
+		{ID: "customfield_10003", Name: CFNameEpicName},
+		{ID: "customfield_10004", Name: CFNameEpicLink},
+		{ID: "customfield_10005", Name: CFNameSprint},
+		{ID: "customfield_10006", Name: CFNameSprint},
+		{ID: "status", Name: CFNameStatus},
+		{ID: "customfield_10007", Name: CFNameRank},
+	}
+	tests := []struct {
+		name         string
+		customFields map[string]string
+		expectedIDs  map[string]string
+		warnings     int
+		errContains  []string
+	}{
+		{"AmbiguousName", nil, nil, 5, []string{`field "sprint" is ambiguous as 2 fields are named "Sprint" (customfield_10005, customfield_10006)`}},
+		{"ConfiguredNameAndID", map[string]string{"github_number": "GH Number", "sprint": "customfield_10006"},
+			map[string]string{CFNameGitHubNumber: "customfield_10002", CFNameSprint: "customfield_10006", CFNameGitHubReporter: ""}, 4, nil},
+		{"MissingConfiguredOptionalField", map[string]string{"sprint": "customfield_10006", "github_status": "State"}, nil, 4,
+			[]string{`field "github_status" was not found in Jira using "State"`}},
+		{"UnknownKey", map[string]string{"sprint": "customfield_10006", "story_points": "customfield_10008"}, nil, 5,
+			[]string{`unknown field "story_points"`}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ids, warnings, err := resolveCustomFields(jiraFields, tt.customFields)
+			if len(tt.errContains) == 0 && err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			for _, expected := range tt.errContains {
+				if err == nil || !strings.Contains(err.Error(), expected) {
+					t.Errorf("resolveCustomFields() error = %v, expecting it to contain %q", err, expected)
+				}
+			}
+			if len(warnings) != tt.warnings {
+				t.Errorf("resolveCustomFields() warnings = %v, expecting %d warnings", warnings, tt.warnings)
+			}
+			for name, id := range tt.expectedIDs {
+				if ids[name] != id {
+					t.Errorf("resolveCustomFields() ID of %q = %q, expecting %q", name, ids[name], id)
+				}
+			}
+		})
+	}
+}
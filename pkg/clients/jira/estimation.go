@@ -0,0 +1,101 @@
+package jira
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	jiralib "github.com/andygrunwald/go-jira"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+)
+
+// boardConfiguration is the part of a board configuration describing how issues are estimated
+type boardConfiguration struct {
+	Estimation struct {
+		// Type is either "field" or "issueCount"
+		Type  string `json:"type"`
+		Field struct {
+			FieldID     string `json:"fieldId"`
+			DisplayName string `json:"displayName"`
+		} `json:"field"`
+	} `json:"estimation"`
+}
+
+// getBoardEstimationField returns the ID of the field used by the board to estimate issues,
+// an empty string is returned if the board does not estimate issues using a field.
+//
+// JIRA API docs: https://developer.atlassian.com/cloud/jira/software/rest/#api-rest-agile-1-0-board-boardId-configuration-get
+func (c *Client) getBoardEstimationField() (string, error) {
+	req, err := c.JiraClient.NewRequest("GET", fmt.Sprintf("/rest/agile/1.0/board/%d/configuration", c.BoardID), nil)
+	if err != nil {
+		return "", errors.Wrapf(err, "failed to get configuration of board %d", c.BoardID)
+	}
+	var config boardConfiguration
+	resp, err := c.JiraClient.Do(req, &config)
+	if err != nil {
+		err = jiralib.NewJiraError(resp, err)
+		return "", errors.Wrapf(err, "failed to get configuration of board %d", c.BoardID)
+	}
+	if config.Estimation.Type != "field" {
+		return "", nil
+	}
+	return config.Estimation.Field.FieldID, nil
+}
+
+// initEstimableIssueTypes sets issue types supporting estimation.
+//
+// If EstimableIssueTypes is empty they are discovered from the board estimation field and the issue types of
+// the project exposing this field on their create screen. As estimation is optional, discovery failures are
+// logged and no issue type is considered as estimable.
+func (c *Client) initEstimableIssueTypes() {
+	c.estimableIssueTypes = make(map[string]bool)
+	if len(c.EstimableIssueTypes) > 0 {
+		for _, issueType := range c.EstimableIssueTypes {
+			c.estimableIssueTypes[strings.ToLower(issueType)] = true
+		}
+		return
+	}
+	err := c.discoverEstimableIssueTypes()
+	if err != nil {
+		logrus.Warnf("Failed to discover issue types supporting estimation, estimates will not be synchronized: %v", err)
+		c.estimableIssueTypes = make(map[string]bool)
+	}
+}
+
+// discoverEstimableIssueTypes adds issue types of the project exposing the board estimation field
+func (c *Client) discoverEstimableIssueTypes() error {
+	fieldID, err := c.getBoardEstimationField()
+	if err != nil || fieldID == "" {
+		return err
+	}
+	meta, _, err := c.JiraClient.Issue.GetCreateMeta(c.ProjectKey)
+	if err != nil {
+		return errors.Wrapf(err, "failed to get issue types of project %q", c.ProjectKey)
+	}
+	project := meta.GetProjectWithKey(c.ProjectKey)
+	if project == nil {
+		return errors.Errorf("failed to get issue types of project %q: project not found", c.ProjectKey)
+	}
+	for _, issueType := range project.IssueTypes {
+		if _, ok := issueType.Fields[fieldID]; ok {
+			c.estimableIssueTypes[strings.ToLower(issueType.Name)] = true
+		}
+	}
+	return nil
+}
+
+// IsIssueTypeEstimable checks if issues of the given type support estimation on the board
+func (c *Client) IsIssueTypeEstimable(issueTypeName string) bool {
+	return c.estimableIssueTypes[strings.ToLower(issueTypeName)]
+}
+
+// GetEstimableIssueTypes returns the sorted lower-cased names of issue types supporting estimation on the board
+func (c *Client) GetEstimableIssueTypes() []string {
+	issueTypes := make([]string, 0, len(c.estimableIssueTypes))
+	for issueType := range c.estimableIssueTypes {
+		issueTypes = append(issueTypes, issueType)
+	}
+	sort.Strings(issueTypes)
+	return issueTypes
+}
@@ -0,0 +1,246 @@
+package jira
+
+import (
+	"context"
+	"io"
+	"time"
+
+	jiralib "github.com/andygrunwald/go-jira"
+)
+
+// InstrumentedClient wraps an API and reports each call with its duration and error to the OnCall function.
+type InstrumentedClient struct {
+	API
+	// OnCall is called after each API call with the API method name, the call duration and the returned error
+	OnCall func(method string, duration time.Duration, err error)
+}
+
+func (c *InstrumentedClient) onCall(method string, start time.Time, err error) {
+	if c.OnCall != nil {
+		c.OnCall(method, time.Since(start), err)
+	}
+}
+
+// ListSprints reports the call
+func (c *InstrumentedClient) ListSprints(ctx context.Context) ([]jiralib.Sprint, error) {
+	start := time.Now()
+	result, err := c.API.ListSprints(ctx)
+	c.onCall("ListSprints", start, err)
+	return result, err
+}
+
+// CreateSprint reports the call
+func (c *InstrumentedClient) CreateSprint(name string, goal string, startDate, endDate *time.Time) (*jiralib.Sprint, error) {
+	start := time.Now()
+	result, err := c.API.CreateSprint(name, goal, startDate, endDate)
+	c.onCall("CreateSprint", start, err)
+	return result, err
+}
+
+// UpdateSprint reports the call
+func (c *InstrumentedClient) UpdateSprint(sprint *jiralib.Sprint) (*jiralib.Sprint, error) {
+	start := time.Now()
+	result, err := c.API.UpdateSprint(sprint)
+	c.onCall("UpdateSprint", start, err)
+	return result, err
+}
+
+// GetProjectVersions reports the call
+func (c *InstrumentedClient) GetProjectVersions() ([]*Version, error) {
+	start := time.Now()
+	result, err := c.API.GetProjectVersions()
+	c.onCall("GetProjectVersions", start, err)
+	return result, err
+}
+
+// CreateVersion reports the call
+func (c *InstrumentedClient) CreateVersion(name, description string, projectID int, released, archived bool, startDate, dueDate, releaseDate *time.Time) (*Version, error) {
+	start := time.Now()
+	result, err := c.API.CreateVersion(name, description, projectID, released, archived, startDate, dueDate, releaseDate)
+	c.onCall("CreateVersion", start, err)
+	return result, err
+}
+
+// UpdateVersion reports the call
+func (c *InstrumentedClient) UpdateVersion(version *Version) (*Version, error) {
+	start := time.Now()
+	result, err := c.API.UpdateVersion(version)
+	c.onCall("UpdateVersion", start, err)
+	return result, err
+}
+
+// GetProjectID reports the call
+func (c *InstrumentedClient) GetProjectID() (int, error) {
+	start := time.Now()
+	result, err := c.API.GetProjectID()
+	c.onCall("GetProjectID", start, err)
+	return result, err
+}
+
+// GetIssueFromGithubID reports the call
+func (c *InstrumentedClient) GetIssueFromGithubID(ghIssueID int64) (*jiralib.Issue, error) {
+	start := time.Now()
+	result, err := c.API.GetIssueFromGithubID(ghIssueID)
+	c.onCall("GetIssueFromGithubID", start, err)
+	return result, err
+}
+
+// ListOpenIssuesWithGithubID reports the call
+func (c *InstrumentedClient) ListOpenIssuesWithGithubID(jql string) ([]jiralib.Issue, error) {
+	start := time.Now()
+	result, err := c.API.ListOpenIssuesWithGithubID(jql)
+	c.onCall("ListOpenIssuesWithGithubID", start, err)
+	return result, err
+}
+
+// AddIssueLabel reports the call
+func (c *InstrumentedClient) AddIssueLabel(issueKeyOrID, label string) error {
+	start := time.Now()
+	err := c.API.AddIssueLabel(issueKeyOrID, label)
+	c.onCall("AddIssueLabel", start, err)
+	return err
+}
+
+// UpdateIssue reports the call
+func (c *InstrumentedClient) UpdateIssue(issue *jiralib.Issue) (*jiralib.Issue, error) {
+	start := time.Now()
+	result, err := c.API.UpdateIssue(issue)
+	c.onCall("UpdateIssue", start, err)
+	return result, err
+}
+
+// UpdateIssueFixVersion reports the call
+func (c *InstrumentedClient) UpdateIssueFixVersion(issueKeyOrID string, versionsIDs []string) error {
+	start := time.Now()
+	err := c.API.UpdateIssueFixVersion(issueKeyOrID, versionsIDs)
+	c.onCall("UpdateIssueFixVersion", start, err)
+	return err
+}
+
+// CreateIssue reports the call
+func (c *InstrumentedClient) CreateIssue(options CreateIssueOptions) (*jiralib.Issue, error) {
+	start := time.Now()
+	result, err := c.API.CreateIssue(options)
+	c.onCall("CreateIssue", start, err)
+	return result, err
+}
+
+// MoveToBacklog reports the call
+func (c *InstrumentedClient) MoveToBacklog(issuesKeys []string) error {
+	start := time.Now()
+	err := c.API.MoveToBacklog(issuesKeys)
+	c.onCall("MoveToBacklog", start, err)
+	return err
+}
+
+// UpdateIssueEstimate reports the call
+func (c *InstrumentedClient) UpdateIssueEstimate(issueKeyOrID string, estimate float32) error {
+	start := time.Now()
+	err := c.API.UpdateIssueEstimate(issueKeyOrID, estimate)
+	c.onCall("UpdateIssueEstimate", start, err)
+	return err
+}
+
+// GetIssueEstimate reports the call
+func (c *InstrumentedClient) GetIssueEstimate(issueKeyOrID string) (float32, error) {
+	start := time.Now()
+	result, err := c.API.GetIssueEstimate(issueKeyOrID)
+	c.onCall("GetIssueEstimate", start, err)
+	return result, err
+}
+
+// AddRemoteLinkToIssue reports the call
+func (c *InstrumentedClient) AddRemoteLinkToIssue(issueKeyOrID, globalID, title, url string) error {
+	start := time.Now()
+	err := c.API.AddRemoteLinkToIssue(issueKeyOrID, globalID, title, url)
+	c.onCall("AddRemoteLinkToIssue", start, err)
+	return err
+}
+
+// GetIssueRemoteLinks reports the call
+func (c *InstrumentedClient) GetIssueRemoteLinks(issueKeyOrID string) ([]RemoteLink, error) {
+	start := time.Now()
+	result, err := c.API.GetIssueRemoteLinks(issueKeyOrID)
+	c.onCall("GetIssueRemoteLinks", start, err)
+	return result, err
+}
+
+// TransitionIssue reports the call
+func (c *InstrumentedClient) TransitionIssue(issueKeyOrID, transitionName string) error {
+	start := time.Now()
+	err := c.API.TransitionIssue(issueKeyOrID, transitionName)
+	c.onCall("TransitionIssue", start, err)
+	return err
+}
+
+// TransitionIssueWithFields reports the call
+func (c *InstrumentedClient) TransitionIssueWithFields(issueKeyOrID, transitionName string, fields map[string]interface{}) error {
+	start := time.Now()
+	err := c.API.TransitionIssueWithFields(issueKeyOrID, transitionName, fields)
+	c.onCall("TransitionIssueWithFields", start, err)
+	return err
+}
+
+// GetIssueTransitions reports the call
+func (c *InstrumentedClient) GetIssueTransitions(issueKeyOrID string) ([]jiralib.Transition, error) {
+	start := time.Now()
+	result, err := c.API.GetIssueTransitions(issueKeyOrID)
+	c.onCall("GetIssueTransitions", start, err)
+	return result, err
+}
+
+// GetIssueStatus reports the call
+func (c *InstrumentedClient) GetIssueStatus(issueKeyOrID string) (*jiralib.Status, error) {
+	start := time.Now()
+	result, err := c.API.GetIssueStatus(issueKeyOrID)
+	c.onCall("GetIssueStatus", start, err)
+	return result, err
+}
+
+// GetLastStatusChange reports the call
+func (c *InstrumentedClient) GetLastStatusChange(issueKeyOrID string) (time.Time, error) {
+	start := time.Now()
+	result, err := c.API.GetLastStatusChange(issueKeyOrID)
+	c.onCall("GetLastStatusChange", start, err)
+	return result, err
+}
+
+// RankIssues reports the call
+func (c *InstrumentedClient) RankIssues(issuesKeys []string, rankBeforeIssue, rankAfterIssue string) error {
+	start := time.Now()
+	err := c.API.RankIssues(issuesKeys, rankBeforeIssue, rankAfterIssue)
+	c.onCall("RankIssues", start, err)
+	return err
+}
+
+// AddComment reports the call
+func (c *InstrumentedClient) AddComment(issueKeyOrID, body string) (*jiralib.Comment, error) {
+	start := time.Now()
+	result, err := c.API.AddComment(issueKeyOrID, body)
+	c.onCall("AddComment", start, err)
+	return result, err
+}
+
+// UpdateComment reports the call
+func (c *InstrumentedClient) UpdateComment(issueKeyOrID, commentID, body string) (*jiralib.Comment, error) {
+	start := time.Now()
+	result, err := c.API.UpdateComment(issueKeyOrID, commentID, body)
+	c.onCall("UpdateComment", start, err)
+	return result, err
+}
+
+// AddAttachment reports the call
+func (c *InstrumentedClient) AddAttachment(issueKeyOrID, filename string, content io.Reader) (*jiralib.Attachment, error) {
+	start := time.Now()
+	result, err := c.API.AddAttachment(issueKeyOrID, filename, content)
+	c.onCall("AddAttachment", start, err)
+	return result, err
+}
+
+// FindUserByEmail reports the call
+func (c *InstrumentedClient) FindUserByEmail(email string) (*jiralib.User, error) {
+	start := time.Now()
+	result, err := c.API.FindUserByEmail(email)
+	c.onCall("FindUserByEmail", start, err)
+	return result, err
+}
@@ -2,6 +2,7 @@ package jira
 
 import (
 	"fmt"
+	"net/http"
 	"strings"
 	"time"
 
@@ -14,7 +15,7 @@ import (
 //
 // The returned issue may be nil if none was found.
 func (c *Client) GetIssueFromGithubID(ghIssueID int64) (*jiralib.Issue, error) {
-	issues, _, err := c.JiraClient.Issue.Search(fmt.Sprintf("project = '%s' AND 'GitHub ID' = %d", c.ProjectKey, ghIssueID), &jiralib.SearchOptions{
+	issues, _, err := c.JiraClient.Issue.Search(fmt.Sprintf("project = '%s' AND %s = %d", c.ProjectKey, jqlField(c.GetCustomFieldID(CFNameGitHubID)), ghIssueID), &jiralib.SearchOptions{
 		Fields:     []string{"*all"},
 		MaxResults: 1,
 	})
@@ -27,6 +28,51 @@ func (c *Client) GetIssueFromGithubID(ghIssueID int64) (*jiralib.Issue, error) {
 	return &issues[0], nil
 }
 
+// ListOpenIssuesWithGithubID returns issues of the project that have a 'GitHub ID' custom field and are not done.
+//
+// jql is an optional JQL clause further restricting the returned issues.
+// Returned issues only contain their summary, status, labels, comments and GitHub custom fields.
+func (c *Client) ListOpenIssuesWithGithubID(jql string) ([]jiralib.Issue, error) {
+	issues := make([]jiralib.Issue, 0)
+	fields := []string{"summary", "status", "labels", "comment", c.GetCustomFieldID(CFNameGitHubID)}
+	if id := c.GetCustomFieldID(CFNameGitHubNumber); id != "" {
+		fields = append(fields, id)
+	}
+	query := fmt.Sprintf("project = '%s' AND %s is not EMPTY AND statusCategory != Done", c.ProjectKey, jqlField(c.GetCustomFieldID(CFNameGitHubID)))
+	if jql != "" {
+		query = fmt.Sprintf("%s AND (%s)", query, jql)
+	}
+	err := c.JiraClient.Issue.SearchPages(query, &jiralib.SearchOptions{
+		Fields:     fields,
+		MaxResults: 100,
+	}, func(issue jiralib.Issue) error {
+		issues = append(issues, issue)
+		return nil
+	})
+	return issues, errors.Wrapf(err, "failed to list open Jira issues with a GitHub ID")
+}
+
+// AddIssueLabel adds a label to an issue, existing labels are kept
+//
+// JIRA API docs: https://docs.atlassian.com/jira/REST/latest/#api/2/issue-editIssue
+func (c *Client) AddIssueLabel(issueKeyOrID, label string) error {
+	reqBody := map[string]interface{}{
+		"update": map[string]interface{}{
+			"labels": []map[string]string{{"add": label}},
+		},
+	}
+	req, err := c.JiraClient.NewRequest("PUT", fmt.Sprintf("/rest/api/2/issue/%s", issueKeyOrID), reqBody)
+	if err != nil {
+		return errors.Wrapf(err, "failed to add label %q to issue %q", label, issueKeyOrID)
+	}
+	resp, err := c.JiraClient.Do(req, nil)
+	if err != nil {
+		err = jiralib.NewJiraError(resp, err)
+		return errors.Wrapf(err, "failed to add label %q to issue %q", label, issueKeyOrID)
+	}
+	return nil
+}
+
 // UpdateIssue will update a given issue.
 //
 // JIRA API docs: https://developer.atlassian.com/cloud/jira/platform/rest/v3/?utm_source=/cloud/jira/platform/rest/&utm_medium=302#api-api-3-issue-id-put
@@ -39,58 +85,87 @@ func (c *Client) UpdateIssue(issue *jiralib.Issue) (*jiralib.Issue, error) {
 		issue.Fields.Unknowns = make(map[string]interface{})
 	}
 	issueKey := issue.Key
-	issue.Fields.Unknowns[c.GetCustomFieldID(CFNameGitHubLastIssueSync)] = time.Now().Format(issueSyncDateFormat)
+	c.setCustomField(issue.Fields.Unknowns, CFNameGitHubLastIssueSync, time.Now().Format(issueSyncDateFormat))
 	issue, _, err := c.JiraClient.Issue.Update(issue)
 	return issue, errors.Wrapf(err, "failed to update issue %q", issueKey)
 }
 
+// CreateIssueOptions are the fields of an issue created by CreateIssue
+type CreateIssueOptions struct {
+	IssueType   string
+	Summary     string
+	Description string
+	// EpicKey is the key of the epic of the issue, it may be empty
+	EpicKey    string
+	Components []string
+	Labels     []string
+	// Sprint is the ID of the sprint of the issue, it may be nil
+	Sprint         *int
+	GithubID       int64
+	GithubNumber   int
+	GithubLabels   []string
+	GithubStatus   string
+	GithubReporter string
+	// Reporter and Assignee are optional, the reporter defaults to the Jira user used by the synchronization
+	Reporter *jiralib.User
+	Assignee *jiralib.User
+	// Fields are additional fields values indexed by field ID, such as the priority, they may override other fields
+	Fields map[string]interface{}
+}
+
 // CreateIssue creates an issue or a sub-task from a JSON representation.
 //
 // JIRA API docs: https://docs.atlassian.com/jira/REST/latest/#api/2/issue-createIssues
-func (c *Client) CreateIssue(issueType, summary, description, epicKey string, components []string, sprint *int, githubID int64, githubNumber int, githubLabels []string, githubStatus string) (*jiralib.Issue, error) {
+func (c *Client) CreateIssue(options CreateIssueOptions) (*jiralib.Issue, error) {
 
 	issue := &jiralib.Issue{
 		Fields: &jiralib.IssueFields{
 			Type: jiralib.IssueType{
-				Name: issueType,
+				Name: options.IssueType,
 			},
 			Project: jiralib.Project{
 				Key: c.ProjectKey,
 			},
-			Summary:     summary,
-			Description: description,
-			Unknowns: map[string]interface{}{
-				c.customFieldsIDs[CFNameGitHubID]:            githubID,
-				c.customFieldsIDs[CFNameGitHubNumber]:        githubNumber,
-				c.customFieldsIDs[CFNameGitHubStatus]:        githubStatus,
-				c.customFieldsIDs[CFNameGitHubLabels]:        strings.Join(githubLabels, " "),
-				c.customFieldsIDs[CFNameGitHubLastIssueSync]: time.Now().Format(issueSyncDateFormat),
-			},
+			Summary:     options.Summary,
+			Description: options.Description,
+			Unknowns:    make(map[string]interface{}),
+			Reporter:    options.Reporter,
+			Assignee:    options.Assignee,
 		},
 	}
+	c.setCustomField(issue.Fields.Unknowns, CFNameGitHubID, options.GithubID)
+	c.setCustomField(issue.Fields.Unknowns, CFNameGitHubNumber, options.GithubNumber)
+	c.setCustomField(issue.Fields.Unknowns, CFNameGitHubStatus, options.GithubStatus)
+	c.setCustomField(issue.Fields.Unknowns, CFNameGitHubLabels, strings.Join(options.GithubLabels, " "))
+	c.setCustomField(issue.Fields.Unknowns, CFNameGitHubReporter, options.GithubReporter)
+	c.setCustomField(issue.Fields.Unknowns, CFNameGitHubLastIssueSync, time.Now().Format(issueSyncDateFormat))
 
-	if issueType == "Epic" {
-		issue.Fields.Unknowns[c.customFieldsIDs[CFNameEpicName]] = summary
+	if options.IssueType == "Epic" {
+		c.setCustomField(issue.Fields.Unknowns, CFNameEpicName, options.Summary)
 	}
-	if epicKey != "" {
-		issue.Fields.Unknowns[c.customFieldsIDs[CFNameEpicLink]] = epicKey
+	if options.EpicKey != "" {
+		c.setCustomField(issue.Fields.Unknowns, CFNameEpicLink, options.EpicKey)
 	}
-	if sprint != nil {
-		issue.Fields.Unknowns[c.customFieldsIDs[CFNameSprint]] = *sprint
+	if options.Sprint != nil {
+		c.setCustomField(issue.Fields.Unknowns, CFNameSprint, *options.Sprint)
 	}
 
-	jiraComponents := make([]*jiralib.Component, len(components))
-	for i, compName := range components {
+	jiraComponents := make([]*jiralib.Component, len(options.Components))
+	for i, compName := range options.Components {
 		jiraComponents[i] = &jiralib.Component{
 			Name: compName,
 		}
 	}
 	issue.Fields.Components = jiraComponents
+	issue.Fields.Labels = options.Labels
+	for id, value := range options.Fields {
+		issue.Fields.Unknowns[id] = value
+	}
 
 	issue, resp, err := c.JiraClient.Issue.Create(issue)
 	if err != nil {
 		err = jiralib.NewJiraError(resp, err)
-		return nil, errors.Wrapf(err, "failed to create issue GH-%d", githubNumber)
+		return nil, errors.Wrapf(err, "failed to create issue GH-%d", options.GithubNumber)
 	}
 	return issue, nil
 }
@@ -174,17 +249,86 @@ func (c *Client) GetIssueRemoteLinks(issueKeyOrID string) ([]RemoteLink, error)
 	return remoteLinks, nil
 }
 
+// GetIssueTransitions returns the list of transitions available for the given issue in its current status
+//
+// JIRA API docs: https://docs.atlassian.com/jira/REST/latest/#api/2/issue-getTransitions
+func (c *Client) GetIssueTransitions(issueKeyOrID string) ([]jiralib.Transition, error) {
+	transitions, _, err := c.JiraClient.Issue.GetTransitions(issueKeyOrID)
+	return transitions, errors.Wrapf(err, "failed to get transitions for issue %q", issueKeyOrID)
+}
+
+// GetIssueStatus returns the current status of the given issue
+//
+// JIRA API docs: https://docs.atlassian.com/jira/REST/latest/#api/2/issue-getIssue
+func (c *Client) GetIssueStatus(issueKeyOrID string) (*jiralib.Status, error) {
+	issue, resp, err := c.JiraClient.Issue.Get(issueKeyOrID, &jiralib.GetQueryOptions{Fields: "status"})
+	if err != nil {
+		err = jiralib.NewJiraError(resp, err)
+		return nil, errors.Wrapf(err, "failed to get status of issue %q", issueKeyOrID)
+	}
+	if issue.Fields == nil {
+		return nil, nil
+	}
+	return issue.Fields.Status, nil
+}
+
+// GetLastStatusChange returns the date of the last status change of the given issue, a zero time is returned
+// if its status never changed
+//
+// JIRA API docs: https://docs.atlassian.com/jira/REST/latest/#api/2/issue-getIssue
+func (c *Client) GetLastStatusChange(issueKeyOrID string) (time.Time, error) {
+	issue, resp, err := c.JiraClient.Issue.Get(issueKeyOrID, &jiralib.GetQueryOptions{Fields: "status", Expand: "changelog"})
+	if err != nil {
+		err = jiralib.NewJiraError(resp, err)
+		return time.Time{}, errors.Wrapf(err, "failed to get changelog of issue %q", issueKeyOrID)
+	}
+	var last time.Time
+	if issue.Changelog == nil {
+		return last, nil
+	}
+	for _, history := range issue.Changelog.Histories {
+		for _, item := range history.Items {
+			if item.Field != "status" {
+				continue
+			}
+			created, err := history.CreatedTime()
+			if err != nil {
+				return last, errors.Wrapf(err, "invalid changelog date of issue %q", issueKeyOrID)
+			}
+			if created.After(last) {
+				last = created
+			}
+		}
+	}
+	return last, nil
+}
+
 // TransitionIssue execute transition identified by the given name to the issue
 func (c *Client) TransitionIssue(issueKeyOrID, transitionName string) error {
+	return c.TransitionIssueWithFields(issueKeyOrID, transitionName, nil)
+}
+
+// TransitionIssueWithFields execute transition identified by the given name to the issue setting the given
+// fields of the transition screen, such as the resolution
+//
+// JIRA API docs: https://docs.atlassian.com/jira/REST/latest/#api/2/issue-doTransition
+func (c *Client) TransitionIssueWithFields(issueKeyOrID, transitionName string, fields map[string]interface{}) error {
 
 	transitions, _, err := c.JiraClient.Issue.GetTransitions(issueKeyOrID)
 	if err != nil {
 		return errors.Wrapf(err, "failed to get transitions for issue %q", issueKeyOrID)
 	}
 
+	type transitionPayload struct {
+		Transition jiralib.TransitionPayload `json:"transition"`
+		Fields     map[string]interface{}    `json:"fields,omitempty"`
+	}
 	for _, transition := range transitions {
 		if transition.Name == transitionName {
-			_, err = c.JiraClient.Issue.DoTransition(issueKeyOrID, transition.ID)
+			_, err = c.JiraClient.Issue.DoTransitionWithPayload(issueKeyOrID, transitionPayload{
+				Transition: jiralib.TransitionPayload{ID: transition.ID},
+				Fields:     fields,
+			})
 			return errors.Wrapf(err, "failed to apply transition %q to issue %q", transition.Name, issueKeyOrID)
 		}
 	}
@@ -235,3 +379,43 @@ func (c *Client) UpdateIssueFixVersion(issueKeyOrID string, versionsIDs []string
 	}
 	return nil
 }
+
+// RankIssues moves the given issues before or after a given issue, only one of rankBeforeIssue and rankAfterIssue should be set.
+//
+// JIRA API docs: https://developer.atlassian.com/cloud/jira/software/rest/#api-rest-agile-1-0-issue-rank-put
+func (c *Client) RankIssues(issuesKeys []string, rankBeforeIssue, rankAfterIssue string) error {
+	// JIRA API support max 50 items
+	issuesLimit := 50
+	for len(issuesKeys) > 0 {
+		batch := issuesKeys
+		if len(batch) > issuesLimit {
+			batch = issuesKeys[:issuesLimit]
+		}
+		issuesKeys = issuesKeys[len(batch):]
+		rankRequest := struct {
+			Issues          []string `json:"issues"`
+			RankBeforeIssue string   `json:"rankBeforeIssue,omitempty"`
+			RankAfterIssue  string   `json:"rankAfterIssue,omitempty"`
+		}{
+			Issues:          batch,
+			RankBeforeIssue: rankBeforeIssue,
+			RankAfterIssue:  rankAfterIssue,
+		}
+		req, err := c.JiraClient.NewRequest("PUT", "/rest/agile/1.0/issue/rank", &rankRequest)
+		if err != nil {
+			return errors.Wrapf(err, "failed to rank issues %v", batch)
+		}
+		resp, err := c.JiraClient.Do(req, nil)
+		if err != nil {
+			err = jiralib.NewJiraError(resp, err)
+			return errors.Wrapf(err, "failed to rank issues %v", batch)
+		}
+		if resp.StatusCode == http.StatusMultiStatus {
+			return errors.Errorf("failed to rank some of the issues %v", batch)
+		}
+		// next batch should be ranked after the last issue of this one
+		rankBeforeIssue = ""
+		rankAfterIssue = batch[len(batch)-1]
+	}
+	return nil
+}
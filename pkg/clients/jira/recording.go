@@ -0,0 +1,543 @@
+package jira
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	jiralib "github.com/andygrunwald/go-jira"
+)
+
+// Planned changes actions
+const (
+	PlanActionCreate        = "create"
+	PlanActionUpdate        = "update"
+	PlanActionTransition    = "transition"
+	PlanActionComment       = "comment"
+	PlanActionMoveToBacklog = "move_to_backlog"
+	PlanActionRank          = "rank"
+)
+
+// Planned changes entities
+const (
+	PlanEntitySprint     = "sprint"
+	PlanEntityVersion    = "version"
+	PlanEntityIssue      = "issue"
+	PlanEntityComment    = "comment"
+	PlanEntityRemoteLink = "remote_link"
+	PlanEntityAttachment = "attachment"
+	// PlanEntityGitHubComment is used to record changes on GitHub comments made while synchronizing Jira comments back to GitHub
+	PlanEntityGitHubComment = "github_comment"
+)
+
+// dryRunKeyPrefix is used to build fake keys for issues that would have been created
+const dryRunKeyPrefix = "DRYRUN-"
+
+// Plan is the set of changes applied to Jira by a synchronization, or that would have been applied in dry-run mode
+type Plan struct {
+	Name    string           `json:"name"`
+	Changes []*PlannedChange `json:"changes"`
+}
+
+// PlannedChange is a single write operation applied to Jira, or that would have been applied in dry-run mode
+type PlannedChange struct {
+	Action string                  `json:"action"`
+	Entity string                  `json:"entity"`
+	Target string                  `json:"target"`
+	Fields map[string]*FieldChange `json:"fields,omitempty"`
+}
+
+// FieldChange represents the change of a single field.
+//
+// From is nil when the previous value is unknown or for creations.
+type FieldChange struct {
+	From interface{} `json:"from,omitempty"`
+	To   interface{} `json:"to"`
+}
+
+// WriteText writes a human readable representation of the plan
+func (p *Plan) WriteText(w io.Writer) error {
+	_, err := fmt.Fprintf(w, "Plan for %s: %d change(s)\n", p.Name, len(p.Changes))
+	if err != nil {
+		return err
+	}
+	for _, c := range p.Changes {
+		_, err = fmt.Fprintf(w, "  %s %s %s\n", c.Action, c.Entity, c.Target)
+		if err != nil {
+			return err
+		}
+		fieldNames := make([]string, 0, len(c.Fields))
+		for name := range c.Fields {
+			fieldNames = append(fieldNames, name)
+		}
+		sort.Strings(fieldNames)
+		for _, name := range fieldNames {
+			fc := c.Fields[name]
+			if fc.From != nil {
+				_, err = fmt.Fprintf(w, "    %s: %s -> %s\n", name, formatPlanValue(fc.From), formatPlanValue(fc.To))
+			} else {
+				_, err = fmt.Fprintf(w, "    %s: %s\n", name, formatPlanValue(fc.To))
+			}
+			if err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func formatPlanValue(v interface{}) string {
+	const maxLen = 80
+	s := strings.Replace(fmt.Sprintf("%v", v), "\n", `\n`, -1)
+	if len(s) > maxLen {
+		s = s[:maxLen] + "..."
+	}
+	return fmt.Sprintf("%q", s)
+}
+
+// RecordingClient wraps an API and records write operations into a Plan.
+//
+// In dry-run mode write operations are only recorded and not applied using the wrapped API, otherwise they are
+// recorded once successfully applied. Read operations always go through the wrapped API.
+type RecordingClient struct {
+	API
+	Plan   *Plan
+	DryRun bool
+
+	lock        sync.Mutex
+	lastID      int
+	knownIssues map[string]*jiralib.Issue
+	fakeKeys    map[string]struct{}
+}
+
+// NewRecordingClient creates a RecordingClient wrapping the given API and recording its changes into a plan with the given name
+func NewRecordingClient(api API, planName string, dryRun bool) *RecordingClient {
+	return &RecordingClient{
+		API:         api,
+		DryRun:      dryRun,
+		Plan:        &Plan{Name: planName, Changes: make([]*PlannedChange, 0)},
+		knownIssues: make(map[string]*jiralib.Issue),
+		fakeKeys:    make(map[string]struct{}),
+	}
+}
+
+// Record adds a change to the plan
+func (c *RecordingClient) Record(action, entity, target string, fields map[string]*FieldChange) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	c.Plan.Changes = append(c.Plan.Changes, &PlannedChange{Action: action, Entity: entity, Target: target, Fields: fields})
+}
+
+// recordIfApplied adds a change to the plan unless applying it failed
+func (c *RecordingClient) recordIfApplied(err error, action, entity, target string, fields map[string]*FieldChange) {
+	if err == nil {
+		c.Record(action, entity, target, fields)
+	}
+}
+
+func (c *RecordingClient) nextFakeKey() (string, int) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	c.lastID++
+	key := fmt.Sprintf("%s%d", dryRunKeyPrefix, c.lastID)
+	c.fakeKeys[key] = struct{}{}
+	return key, c.lastID
+}
+
+func (c *RecordingClient) isFake(issueKeyOrID string) bool {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	_, ok := c.fakeKeys[issueKeyOrID]
+	return ok
+}
+
+// customFieldName returns the name of a registered custom field from its ID or the ID itself if not found
+func (c *RecordingClient) customFieldName(id string) string {
+	for _, cf := range registeredCustomFields {
+		if id != "" && c.GetCustomFieldID(cf.name) == id {
+			return cf.name
+		}
+	}
+	return id
+}
+
+// CreateSprint records a sprint creation
+func (c *RecordingClient) CreateSprint(name string, goal string, startDate, endDate *time.Time) (*jiralib.Sprint, error) {
+	changes := map[string]*FieldChange{
+		"goal":      {To: goal},
+		"startDate": {To: startDate},
+		"endDate":   {To: endDate},
+	}
+	if !c.DryRun {
+		result, err := c.API.CreateSprint(name, goal, startDate, endDate)
+		c.recordIfApplied(err, PlanActionCreate, PlanEntitySprint, name, changes)
+		return result, err
+	}
+	c.Record(PlanActionCreate, PlanEntitySprint, name, changes)
+	_, id := c.nextFakeKey()
+	return &jiralib.Sprint{ID: -id, Name: name, StartDate: startDate, EndDate: endDate, State: "future"}, nil
+}
+
+// UpdateSprint records a sprint update
+func (c *RecordingClient) UpdateSprint(sprint *jiralib.Sprint) (*jiralib.Sprint, error) {
+	changes := map[string]*FieldChange{
+		"state":        {To: sprint.State},
+		"startDate":    {To: sprint.StartDate},
+		"endDate":      {To: sprint.EndDate},
+		"completeDate": {To: sprint.CompleteDate},
+	}
+	if !c.DryRun {
+		result, err := c.API.UpdateSprint(sprint)
+		c.recordIfApplied(err, PlanActionUpdate, PlanEntitySprint, sprint.Name, changes)
+		return result, err
+	}
+	c.Record(PlanActionUpdate, PlanEntitySprint, sprint.Name, changes)
+	return sprint, nil
+}
+
+// CreateVersion records a version creation
+func (c *RecordingClient) CreateVersion(name, description string, projectID int, released, archived bool, startDate, dueDate, releaseDate *time.Time) (*Version, error) {
+	changes := map[string]*FieldChange{
+		"description": {To: description},
+		"released":    {To: released},
+		"archived":    {To: archived},
+		"startDate":   {To: startDate},
+		"dueDate":     {To: dueDate},
+		"releaseDate": {To: releaseDate},
+	}
+	if !c.DryRun {
+		result, err := c.API.CreateVersion(name, description, projectID, released, archived, startDate, dueDate, releaseDate)
+		c.recordIfApplied(err, PlanActionCreate, PlanEntityVersion, name, changes)
+		return result, err
+	}
+	c.Record(PlanActionCreate, PlanEntityVersion, name, changes)
+	key, _ := c.nextFakeKey()
+	return &Version{Version: jiralib.Version{ID: key, Name: name, Description: description, Released: released, Archived: archived, ProjectID: projectID}}, nil
+}
+
+// UpdateVersion records a version update
+func (c *RecordingClient) UpdateVersion(version *Version) (*Version, error) {
+	changes := map[string]*FieldChange{
+		"description":     {To: version.Description},
+		"released":        {To: version.Released},
+		"startDate":       {To: version.StartDate},
+		"userReleaseDate": {To: version.UserReleaseDate},
+		"releaseDate":     {To: version.ReleaseDate},
+	}
+	if !c.DryRun {
+		result, err := c.API.UpdateVersion(version)
+		c.recordIfApplied(err, PlanActionUpdate, PlanEntityVersion, version.Name, changes)
+		return result, err
+	}
+	c.Record(PlanActionUpdate, PlanEntityVersion, version.Name, changes)
+	return version, nil
+}
+
+// GetIssueFromGithubID retrieves the issue using the wrapped API and keeps track of it to compute field changes on updates
+func (c *RecordingClient) GetIssueFromGithubID(ghIssueID int64) (*jiralib.Issue, error) {
+	issue, err := c.API.GetIssueFromGithubID(ghIssueID)
+	if err == nil && issue != nil {
+		c.lock.Lock()
+		c.knownIssues[issue.Key] = issue
+		c.lock.Unlock()
+	}
+	return issue, err
+}
+
+// UpdateIssue records an issue update with its field level changes
+func (c *RecordingClient) UpdateIssue(issue *jiralib.Issue) (*jiralib.Issue, error) {
+	c.lock.Lock()
+	previous := c.knownIssues[issue.Key]
+	c.lock.Unlock()
+
+	fields := make(map[string]*FieldChange)
+	if issue.Fields != nil {
+		var prevFields *jiralib.IssueFields
+		if previous != nil {
+			prevFields = previous.Fields
+		}
+		if issue.Fields.Summary != "" {
+			fc := &FieldChange{To: issue.Fields.Summary}
+			if prevFields != nil {
+				fc.From = prevFields.Summary
+			}
+			fields["summary"] = fc
+		}
+		if issue.Fields.Description != "" {
+			fc := &FieldChange{To: issue.Fields.Description}
+			if prevFields != nil {
+				fc.From = prevFields.Description
+			}
+			fields["description"] = fc
+		}
+		if issue.Fields.Components != nil {
+			fields["components"] = &FieldChange{To: componentsNames(issue.Fields.Components)}
+		}
+		if issue.Fields.Reporter != nil {
+			fc := &FieldChange{To: c.GetUserID(issue.Fields.Reporter)}
+			if prevFields != nil {
+				fc.From = c.GetUserID(prevFields.Reporter)
+			}
+			fields["reporter"] = fc
+		}
+		if issue.Fields.Assignee != nil {
+			fc := &FieldChange{To: c.GetUserID(issue.Fields.Assignee)}
+			if prevFields != nil {
+				fc.From = c.GetUserID(prevFields.Assignee)
+			}
+			fields["assignee"] = fc
+		}
+		for id, v := range issue.Fields.Unknowns {
+			fc := &FieldChange{To: v}
+			if prevFields != nil {
+				fc.From = prevFields.Unknowns[id]
+			}
+			fields[c.customFieldName(id)] = fc
+		}
+	}
+	if !c.DryRun {
+		result, err := c.API.UpdateIssue(issue)
+		c.recordIfApplied(err, PlanActionUpdate, PlanEntityIssue, issue.Key, fields)
+		return result, err
+	}
+	c.Record(PlanActionUpdate, PlanEntityIssue, issue.Key, fields)
+	ret := *issue
+	return &ret, nil
+}
+
+func componentsNames(components []*jiralib.Component) []string {
+	names := make([]string, len(components))
+	for i, comp := range components {
+		names[i] = comp.Name
+	}
+	return names
+}
+
+// UpdateIssueFixVersion records an issue fix versions update
+func (c *RecordingClient) UpdateIssueFixVersion(issueKeyOrID string, versionsIDs []string) error {
+	var err error
+	if !c.DryRun {
+		err = c.API.UpdateIssueFixVersion(issueKeyOrID, versionsIDs)
+	}
+	c.recordIfApplied(err, PlanActionUpdate, PlanEntityIssue, issueKeyOrID, map[string]*FieldChange{
+		"fixVersions": {To: versionsIDs},
+	})
+	return err
+}
+
+// CreateIssue records an issue creation
+func (c *RecordingClient) CreateIssue(options CreateIssueOptions) (*jiralib.Issue, error) {
+	changes := map[string]*FieldChange{
+		"type":               {To: options.IssueType},
+		"summary":            {To: options.Summary},
+		"description":        {To: options.Description},
+		"components":         {To: options.Components},
+		"labels":             {To: options.Labels},
+		CFNameGitHubID:       {To: options.GithubID},
+		CFNameGitHubNumber:   {To: options.GithubNumber},
+		CFNameGitHubLabels:   {To: strings.Join(options.GithubLabels, " ")},
+		CFNameGitHubStatus:   {To: options.GithubStatus},
+		CFNameGitHubReporter: {To: options.GithubReporter},
+		CFNameEpicLink:       {To: options.EpicKey},
+		CFNameSprint:         {To: options.Sprint},
+	}
+	if options.Reporter != nil {
+		changes["reporter"] = &FieldChange{To: c.GetUserID(options.Reporter)}
+	}
+	if options.Assignee != nil {
+		changes["assignee"] = &FieldChange{To: c.GetUserID(options.Assignee)}
+	}
+	for id, value := range options.Fields {
+		changes[c.customFieldName(id)] = &FieldChange{To: value}
+	}
+	if !c.DryRun {
+		issue, err := c.API.CreateIssue(options)
+		if err == nil {
+			c.Record(PlanActionCreate, PlanEntityIssue, fmt.Sprintf("%s (GH-%d)", issue.Key, options.GithubNumber), changes)
+		}
+		return issue, err
+	}
+	key, id := c.nextFakeKey()
+	c.Record(PlanActionCreate, PlanEntityIssue, fmt.Sprintf("%s (GH-%d)", key, options.GithubNumber), changes)
+	return &jiralib.Issue{Key: key, ID: fmt.Sprintf("%d", -id)}, nil
+}
+
+// AddIssueLabel records a label addition to an issue
+func (c *RecordingClient) AddIssueLabel(issueKeyOrID, label string) error {
+	var err error
+	if !c.DryRun {
+		err = c.API.AddIssueLabel(issueKeyOrID, label)
+	}
+	c.recordIfApplied(err, PlanActionUpdate, PlanEntityIssue, issueKeyOrID, map[string]*FieldChange{
+		"labels": {To: label},
+	})
+	return err
+}
+
+// MoveToBacklog records issues moves to the backlog
+func (c *RecordingClient) MoveToBacklog(issuesKeys []string) error {
+	var err error
+	if !c.DryRun {
+		err = c.API.MoveToBacklog(issuesKeys)
+	}
+	for _, key := range issuesKeys {
+		c.recordIfApplied(err, PlanActionMoveToBacklog, PlanEntityIssue, key, nil)
+	}
+	return err
+}
+
+// UpdateIssueEstimate records an issue estimate update
+func (c *RecordingClient) UpdateIssueEstimate(issueKeyOrID string, estimate float32) error {
+	var err error
+	if !c.DryRun {
+		err = c.API.UpdateIssueEstimate(issueKeyOrID, estimate)
+	}
+	c.recordIfApplied(err, PlanActionUpdate, PlanEntityIssue, issueKeyOrID, map[string]*FieldChange{
+		"estimate": {To: estimate},
+	})
+	return err
+}
+
+// GetIssueEstimate returns the estimate from the wrapped API unless the issue would have been created in dry-run mode
+func (c *RecordingClient) GetIssueEstimate(issueKeyOrID string) (float32, error) {
+	if c.isFake(issueKeyOrID) {
+		return 0, nil
+	}
+	return c.API.GetIssueEstimate(issueKeyOrID)
+}
+
+// AddRemoteLinkToIssue records a remote link creation
+func (c *RecordingClient) AddRemoteLinkToIssue(issueKeyOrID, globalID, title, url string) error {
+	var err error
+	if !c.DryRun {
+		err = c.API.AddRemoteLinkToIssue(issueKeyOrID, globalID, title, url)
+	}
+	c.recordIfApplied(err, PlanActionCreate, PlanEntityRemoteLink, issueKeyOrID, map[string]*FieldChange{
+		"title": {To: title},
+		"url":   {To: url},
+	})
+	return err
+}
+
+// GetIssueRemoteLinks returns remote links from the wrapped API unless the issue would have been created in dry-run mode
+func (c *RecordingClient) GetIssueRemoteLinks(issueKeyOrID string) ([]RemoteLink, error) {
+	if c.isFake(issueKeyOrID) {
+		return nil, nil
+	}
+	return c.API.GetIssueRemoteLinks(issueKeyOrID)
+}
+
+// TransitionIssue records an issue transition
+func (c *RecordingClient) TransitionIssue(issueKeyOrID, transitionName string) error {
+	var err error
+	if !c.DryRun {
+		err = c.API.TransitionIssue(issueKeyOrID, transitionName)
+	}
+	c.recordIfApplied(err, PlanActionTransition, PlanEntityIssue, issueKeyOrID, map[string]*FieldChange{
+		"transition": {To: transitionName},
+	})
+	return err
+}
+
+// TransitionIssueWithFields records an issue transition and the fields set by the transition
+func (c *RecordingClient) TransitionIssueWithFields(issueKeyOrID, transitionName string, fields map[string]interface{}) error {
+	changes := map[string]*FieldChange{
+		"transition": {To: transitionName},
+	}
+	for name, value := range fields {
+		changes[c.customFieldName(name)] = &FieldChange{To: value}
+	}
+	var err error
+	if !c.DryRun {
+		err = c.API.TransitionIssueWithFields(issueKeyOrID, transitionName, fields)
+	}
+	c.recordIfApplied(err, PlanActionTransition, PlanEntityIssue, issueKeyOrID, changes)
+	return err
+}
+
+// GetIssueTransitions returns transitions from the wrapped API unless the issue would have been created in dry-run mode
+func (c *RecordingClient) GetIssueTransitions(issueKeyOrID string) ([]jiralib.Transition, error) {
+	if c.isFake(issueKeyOrID) {
+		return nil, nil
+	}
+	return c.API.GetIssueTransitions(issueKeyOrID)
+}
+
+// GetIssueStatus returns the status from the wrapped API unless the issue would have been created in dry-run mode
+func (c *RecordingClient) GetIssueStatus(issueKeyOrID string) (*jiralib.Status, error) {
+	if c.isFake(issueKeyOrID) {
+		return nil, nil
+	}
+	return c.API.GetIssueStatus(issueKeyOrID)
+}
+
+// GetLastStatusChange returns the last status change from the wrapped API unless the issue would have been created
+// in dry-run mode
+func (c *RecordingClient) GetLastStatusChange(issueKeyOrID string) (time.Time, error) {
+	if c.isFake(issueKeyOrID) {
+		return time.Time{}, nil
+	}
+	return c.API.GetLastStatusChange(issueKeyOrID)
+}
+
+// RankIssues records issues ranking
+func (c *RecordingClient) RankIssues(issuesKeys []string, rankBeforeIssue, rankAfterIssue string) error {
+	fields := make(map[string]*FieldChange)
+	if rankBeforeIssue != "" {
+		fields["before"] = &FieldChange{To: rankBeforeIssue}
+	}
+	if rankAfterIssue != "" {
+		fields["after"] = &FieldChange{To: rankAfterIssue}
+	}
+	var err error
+	if !c.DryRun {
+		err = c.API.RankIssues(issuesKeys, rankBeforeIssue, rankAfterIssue)
+	}
+	c.recordIfApplied(err, PlanActionRank, PlanEntityIssue, strings.Join(issuesKeys, ","), fields)
+	return err
+}
+
+// AddComment records a comment creation
+func (c *RecordingClient) AddComment(issueKeyOrID, body string) (*jiralib.Comment, error) {
+	changes := map[string]*FieldChange{
+		"body": {To: body},
+	}
+	if !c.DryRun {
+		result, err := c.API.AddComment(issueKeyOrID, body)
+		c.recordIfApplied(err, PlanActionComment, PlanEntityComment, issueKeyOrID, changes)
+		return result, err
+	}
+	c.Record(PlanActionComment, PlanEntityComment, issueKeyOrID, changes)
+	return &jiralib.Comment{Body: body}, nil
+}
+
+// UpdateComment records a comment update
+func (c *RecordingClient) UpdateComment(issueKeyOrID, commentID, body string) (*jiralib.Comment, error) {
+	changes := map[string]*FieldChange{
+		"body": {To: body},
+	}
+	if !c.DryRun {
+		result, err := c.API.UpdateComment(issueKeyOrID, commentID, body)
+		c.recordIfApplied(err, PlanActionUpdate, PlanEntityComment, fmt.Sprintf("%s/%s", issueKeyOrID, commentID), changes)
+		return result, err
+	}
+	c.Record(PlanActionUpdate, PlanEntityComment, fmt.Sprintf("%s/%s", issueKeyOrID, commentID), changes)
+	return &jiralib.Comment{ID: commentID, Body: body}, nil
+}
+
+// AddAttachment records an attachment upload
+func (c *RecordingClient) AddAttachment(issueKeyOrID, filename string, content io.Reader) (*jiralib.Attachment, error) {
+	changes := map[string]*FieldChange{
+		"filename": {To: filename},
+	}
+	if !c.DryRun {
+		result, err := c.API.AddAttachment(issueKeyOrID, filename, content)
+		c.recordIfApplied(err, PlanActionCreate, PlanEntityAttachment, issueKeyOrID, changes)
+		return result, err
+	}
+	c.Record(PlanActionCreate, PlanEntityAttachment, issueKeyOrID, changes)
+	return &jiralib.Attachment{Filename: filename}, nil
+}
@@ -0,0 +1,41 @@
+package jira
+
+import (
+	"testing"
+
+	"github.com/pkg/errors"
+)
+
+// failingAPI fails transitions and succeeds labels additions
+type failingAPI struct {
+	API
+}
+
+func (f *failingAPI) TransitionIssue(issueKeyOrID, transitionName string) error {
+	return errors.New("transition failed")
+}
+
+func (f *failingAPI) AddIssueLabel(issueKeyOrID, label string) error {
+	return nil
+}
+
+func TestRecordingClientRecordsAppliedChanges(t *testing.T) {
+	c := NewRecordingClient(&failingAPI{}, "test", false)
+	if err := c.TransitionIssue("PRJ-1", "Done"); err == nil {
+		t.Fatal("expecting the transition to fail")
+	}
+	if err := c.AddIssueLabel("PRJ-1", "orphan"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(c.Plan.Changes) != 1 || c.Plan.Changes[0].Fields["labels"] == nil {
+		t.Errorf("expecting only the label addition to be recorded, got %d change(s)", len(c.Plan.Changes))
+	}
+
+	dryRun := NewRecordingClient(&failingAPI{}, "test", true)
+	if err := dryRun.TransitionIssue("PRJ-1", "Done"); err != nil {
+		t.Fatalf("unexpected error in dry-run mode: %v", err)
+	}
+	if len(dryRun.Plan.Changes) != 1 {
+		t.Errorf("expecting the transition to be recorded in dry-run mode, got %d change(s)", len(dryRun.Plan.Changes))
+	}
+}
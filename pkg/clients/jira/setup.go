@@ -0,0 +1,280 @@
+package jira
+
+import (
+	"fmt"
+	"net/url"
+	"sort"
+	"strings"
+
+	jiralib "github.com/andygrunwald/go-jira"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+)
+
+// fieldTypes are the Jira types and searchers of fields created by Setup
+var fieldTypes = map[string]struct {
+	typ      string
+	searcher string
+}{
+	fieldTypeNumber:   {"com.atlassian.jira.plugin.system.customfieldtypes:float", "com.atlassian.jira.plugin.system.customfieldtypes:exactnumber"},
+	fieldTypeText:     {"com.atlassian.jira.plugin.system.customfieldtypes:textfield", "com.atlassian.jira.plugin.system.customfieldtypes:textsearcher"},
+	fieldTypeDateTime: {"com.atlassian.jira.plugin.system.customfieldtypes:datetime", "com.atlassian.jira.plugin.system.customfieldtypes:datetimerange"},
+}
+
+// setupField is a field managed by Setup, its ID is empty if it does not exist yet
+type setupField struct {
+	name string
+	id   string
+}
+
+type screenTab struct {
+	ID   int64  `json:"id"`
+	Name string `json:"name"`
+}
+
+type screenField struct {
+	ID string `json:"id"`
+}
+
+// Setup creates the missing custom fields used by the synchronization and adds them to the screens of the project.
+//
+// Fields are identified by their default name unless overridden by CustomFields. Setup is idempotent, it returns
+// descriptions of applied changes. In dry-run mode changes are only described and not applied.
+// It does not require the client to be initialized.
+//
+// Screens schemes are only available through the API of Jira Cloud, on Jira Server fields are created but have to be
+// added to screens manually.
+func (c *Client) Setup(dryRun bool) ([]string, error) {
+	var changes []string
+	fields, err := c.setupFields(dryRun, &changes)
+	if err != nil {
+		return changes, err
+	}
+
+	cloud, err := c.isCloud()
+	if err != nil {
+		return changes, err
+	}
+	if !cloud {
+		logrus.Warnf("Fields can not be added to screens on Jira Server, add them to the screens of project %q manually", c.ProjectKey)
+		return changes, nil
+	}
+
+	projectID, err := c.GetProjectID()
+	if err != nil {
+		return changes, err
+	}
+	screens, err := c.getProjectScreens(projectID)
+	if err != nil {
+		return changes, err
+	}
+	for _, screenID := range screens {
+		err = c.setupScreen(screenID, fields, dryRun, &changes)
+		if err != nil {
+			return changes, err
+		}
+	}
+	return changes, nil
+}
+
+// setupFields creates missing fields, it returns the fields managed by Setup
+func (c *Client) setupFields(dryRun bool, changes *[]string) ([]setupField, error) {
+	jiraFields, _, err := c.JiraClient.Field.GetList()
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get Jira fields")
+	}
+	fieldsIDs := make(map[string]bool, len(jiraFields))
+	fieldsByName := make(map[string][]string, len(jiraFields))
+	for _, field := range jiraFields {
+		fieldsIDs[field.ID] = true
+		fieldsByName[field.Name] = append(fieldsByName[field.Name], field.ID)
+	}
+
+	fields := make([]setupField, 0)
+	for _, cf := range registeredCustomFields {
+		if cf.fieldType == "" {
+			continue
+		}
+		ref := c.CustomFields[cf.key]
+		if ref == "" {
+			ref = cf.name
+		}
+		if fieldsIDs[ref] {
+			fields = append(fields, setupField{name: ref, id: ref})
+			continue
+		}
+		if strings.HasPrefix(ref, "customfield_") {
+			return nil, errors.Errorf("field %q is configured with ID %q which does not exist, use a name to create it", cf.key, ref)
+		}
+		switch matches := fieldsByName[ref]; len(matches) {
+		case 0:
+			var id string
+			if !dryRun {
+				id, err = c.createField(ref, cf.fieldType)
+				if err != nil {
+					return nil, err
+				}
+			}
+			change := fmt.Sprintf("Created %s field %q", cf.fieldType, ref)
+			if id != "" {
+				change += " with ID " + id
+			}
+			*changes = append(*changes, change)
+			fields = append(fields, setupField{name: ref, id: id})
+		case 1:
+			fields = append(fields, setupField{name: ref, id: matches[0]})
+		default:
+			sort.Strings(matches)
+			return nil, errors.Errorf("field %q is ambiguous as %d fields are named %q (%s), set custom_fields.%s to one of these IDs",
+				cf.key, len(matches), ref, strings.Join(matches, ", "), cf.key)
+		}
+	}
+	return fields, nil
+}
+
+// isCloud returns true if the Jira deployment is Jira Cloud
+//
+// JIRA API docs: https://docs.atlassian.com/jira/REST/latest/#api/2/serverInfo-getServerInfo
+func (c *Client) isCloud() (bool, error) {
+	var info struct {
+		DeploymentType string `json:"deploymentType"`
+	}
+	err := c.do("GET", "/rest/api/2/serverInfo", nil, &info)
+	return info.DeploymentType == "Cloud", errors.Wrap(err, "failed to get Jira server information")
+}
+
+// createField creates a custom field of the given type and returns its ID
+//
+// JIRA API docs: https://developer.atlassian.com/cloud/jira/platform/rest/v2/api-group-issue-fields/#api-rest-api-2-field-post
+func (c *Client) createField(name, fieldType string) (string, error) {
+	t := fieldTypes[fieldType]
+	body := map[string]string{
+		"name":        name,
+		"description": "Managed by zenhub-jira-sync",
+		"type":        t.typ,
+		"searcherKey": t.searcher,
+	}
+	var field jiralib.Field
+	err := c.do("POST", "/rest/api/2/field", body, &field)
+	return field.ID, errors.Wrapf(err, "failed to create field %q", name)
+}
+
+// getProjectScreens returns the IDs of screens used by issue types of the given project, including the default,
+// create, edit and view screens.
+//
+// JIRA API docs: https://developer.atlassian.com/cloud/jira/platform/rest/v2/api-group-issue-type-screen-schemes/
+func (c *Client) getProjectScreens(projectID int) ([]int64, error) {
+	var schemes struct {
+		Values []struct {
+			IssueTypeScreenScheme struct {
+				ID string `json:"id"`
+			} `json:"issueTypeScreenScheme"`
+		} `json:"values"`
+	}
+	err := c.do("GET", fmt.Sprintf("/rest/api/2/issuetypescreenscheme/project?projectId=%d", projectID), nil, &schemes)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to get issue type screen scheme of project %q", c.ProjectKey)
+	}
+	if len(schemes.Values) == 0 {
+		return nil, errors.Errorf("no issue type screen scheme found for project %q", c.ProjectKey)
+	}
+
+	screenSchemesIDs := make(map[string]bool)
+	for startAt := 0; ; {
+		var mappings struct {
+			IsLast bool `json:"isLast"`
+			Values []struct {
+				ScreenSchemeID string `json:"screenSchemeId"`
+			} `json:"values"`
+		}
+		err = c.do("GET", fmt.Sprintf("/rest/api/2/issuetypescreenscheme/mapping?issueTypeScreenSchemeId=%s&startAt=%d",
+			url.QueryEscape(schemes.Values[0].IssueTypeScreenScheme.ID), startAt), nil, &mappings)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to get screen schemes of project %q", c.ProjectKey)
+		}
+		for _, m := range mappings.Values {
+			screenSchemesIDs[m.ScreenSchemeID] = true
+		}
+		startAt += len(mappings.Values)
+		if mappings.IsLast || len(mappings.Values) == 0 {
+			break
+		}
+	}
+
+	query := url.Values{}
+	for id := range screenSchemesIDs {
+		query.Add("id", id)
+	}
+	var screenSchemes struct {
+		Values []struct {
+			Screens map[string]int64 `json:"screens"`
+		} `json:"values"`
+	}
+	err = c.do("GET", "/rest/api/2/screenscheme?"+query.Encode(), nil, &screenSchemes)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to get screens of project %q", c.ProjectKey)
+	}
+	screens := make([]int64, 0)
+	known := make(map[int64]bool)
+	for _, scheme := range screenSchemes.Values {
+		for _, screenID := range scheme.Screens {
+			if !known[screenID] {
+				known[screenID] = true
+				screens = append(screens, screenID)
+			}
+		}
+	}
+	sort.Slice(screens, func(i, j int) bool { return screens[i] < screens[j] })
+	return screens, nil
+}
+
+// setupScreen adds the given fields to the first tab of a screen if they are not on any of its tabs
+//
+// JIRA API docs: https://developer.atlassian.com/cloud/jira/platform/rest/v2/api-group-screen-tab-fields/
+func (c *Client) setupScreen(screenID int64, fields []setupField, dryRun bool, changes *[]string) error {
+	var tabs []screenTab
+	err := c.do("GET", fmt.Sprintf("/rest/api/2/screens/%d/tabs", screenID), nil, &tabs)
+	if err != nil {
+		return errors.Wrapf(err, "failed to get tabs of screen %d", screenID)
+	}
+	if len(tabs) == 0 {
+		return errors.Errorf("screen %d has no tab to add fields to", screenID)
+	}
+	present := make(map[string]bool)
+	for _, tab := range tabs {
+		var tabFields []screenField
+		err = c.do("GET", fmt.Sprintf("/rest/api/2/screens/%d/tabs/%d/fields", screenID, tab.ID), nil, &tabFields)
+		if err != nil {
+			return errors.Wrapf(err, "failed to get fields of screen %d", screenID)
+		}
+		for _, f := range tabFields {
+			present[f.ID] = true
+		}
+	}
+	for _, f := range fields {
+		if f.id != "" && present[f.id] {
+			continue
+		}
+		if !dryRun {
+			err = c.do("POST", fmt.Sprintf("/rest/api/2/screens/%d/tabs/%d/fields", screenID, tabs[0].ID), map[string]string{"fieldId": f.id}, nil)
+			if err != nil {
+				return errors.Wrapf(err, "failed to add field %q to screen %d", f.name, screenID)
+			}
+		}
+		*changes = append(*changes, fmt.Sprintf("Added field %q to tab %q of screen %d", f.name, tabs[0].Name, screenID))
+	}
+	return nil
+}
+
+// do sends a request to the Jira API and decodes its JSON response into v if not nil
+func (c *Client) do(method, urlStr string, body, v interface{}) error {
+	req, err := c.JiraClient.NewRequest(method, urlStr, body)
+	if err != nil {
+		return err
+	}
+	resp, err := c.JiraClient.Do(req, v)
+	if err != nil {
+		return jiralib.NewJiraError(resp, err)
+	}
+	return nil
+}
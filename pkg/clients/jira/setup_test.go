@@ -0,0 +1,127 @@
+package jira
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	jiralib "github.com/andygrunwald/go-jira"
+)
+
+// fakeSetupJira serves the Jira API endpoints used by Setup for a project having a single screen with a single tab
+type fakeSetupJira struct {
+	t              *testing.T
+	deploymentType string
+	lock           sync.Mutex
+	fields         []jiralib.Field
+	screenFields   []screenField
+	writes         int
+}
+
+func (f *fakeSetupJira) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	var response interface{}
+	switch r.Method + " " + r.URL.Path {
+	case "GET /rest/api/2/serverInfo":
+		response = map[string]string{"deploymentType": f.deploymentType}
+	case "GET /rest/api/2/field":
+		response = f.fields
+	case "POST /rest/api/2/field":
+		var body map[string]string
+		json.NewDecoder(r.Body).Decode(&body)
+		field := jiralib.Field{ID: fmt.Sprintf("customfield_%d", 10001+len(f.fields)), Name: body["name"], Custom: true}
+		f.fields = append(f.fields, field)
+		f.writes++
+		response = field
+	case "GET /rest/api/2/project/PRJ":
+		response = map[string]string{"id": "10000", "key": "PRJ"}
+	case "GET /rest/api/2/issuetypescreenscheme/project":
+		response = map[string]interface{}{"values": []interface{}{map[string]interface{}{"issueTypeScreenScheme": map[string]string{"id": "1"}}}}
+	case "GET /rest/api/2/issuetypescreenscheme/mapping":
+		response = map[string]interface{}{"isLast": true, "values": []interface{}{map[string]string{"screenSchemeId": "2"}}}
+	case "GET /rest/api/2/screenscheme":
+		response = map[string]interface{}{"values": []interface{}{map[string]interface{}{"screens": map[string]int64{"default": 3}}}}
+	case "GET /rest/api/2/screens/3/tabs":
+		response = []screenTab{{ID: 4, Name: "Field Tab"}}
+	case "GET /rest/api/2/screens/3/tabs/4/fields":
+		response = f.screenFields
+	case "POST /rest/api/2/screens/3/tabs/4/fields":
+		var body map[string]string
+		json.NewDecoder(r.Body).Decode(&body)
+		f.screenFields = append(f.screenFields, screenField{ID: body["fieldId"]})
+		f.writes++
+		response = body
+	default:
+		f.t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+	json.NewEncoder(w).Encode(response)
+}
+
+func newSetupTestClient(t *testing.T, deploymentType string) (*Client, *fakeSetupJira, func()) {
+	fake := &fakeSetupJira{
+		t:              t,
+		deploymentType: deploymentType,
+		fields:         []jiralib.Field{{ID: "customfield_10000", Name: CFNameGitHubID, Custom: true}},
+		screenFields:   []screenField{{ID: "customfield_10000"}},
+	}
+	server := httptest.NewServer(fake)
+	jiraClient, err := jiralib.NewClient(nil, server.URL)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	return &Client{JiraClient: jiraClient, ProjectKey: "PRJ"}, fake, server.Close
+}
+
+func TestSetup(t *testing.T) {
+	// GitHub ID exists and is on the screen, all other optional fields have to be created and added to the screen
+	missingFields := 0
+	for _, cf := range registeredCustomFields {
+		if cf.fieldType != "" && cf.name != CFNameGitHubID {
+			missingFields++
+		}
+	}
+	tests := []struct {
+		name            string
+		deploymentType  string
+		expectedChanges int
+	}{
+		{"Cloud", "Cloud", 2 * missingFields},
+		{"Server", "Server", missingFields},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client, fake, closeServer := newSetupTestClient(t, tt.deploymentType)
+			defer closeServer()
+
+			changes, err := client.Setup(true)
+			if err != nil {
+				t.Fatalf("unexpected error in dry-run mode: %v", err)
+			}
+			if len(changes) != tt.expectedChanges || fake.writes != 0 {
+				t.Errorf("dry-run returned %d change(s) and applied %d, expecting %d change(s) and none applied", len(changes), fake.writes, tt.expectedChanges)
+			}
+
+			changes, err = client.Setup(false)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if len(changes) != tt.expectedChanges || fake.writes != tt.expectedChanges {
+				t.Errorf("setup returned %d change(s) and applied %d, expecting %d", len(changes), fake.writes, tt.expectedChanges)
+			}
+
+			changes, err = client.Setup(false)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if len(changes) != 0 || fake.writes != tt.expectedChanges {
+				t.Errorf("setup is not idempotent, second run returned changes %v", changes)
+			}
+		})
+	}
+}
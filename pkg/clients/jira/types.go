@@ -2,6 +2,7 @@ package jira
 
 import (
 	"context"
+	"io"
 	"time"
 
 	jiralib "github.com/andygrunwald/go-jira"
@@ -42,6 +43,17 @@ type API interface {
 	// The returned issue may be nil if none was found.
 	GetIssueFromGithubID(ghIssueID int64) (*jiralib.Issue, error)
 
+	// ListOpenIssuesWithGithubID returns issues of the project that have a 'GitHub ID' custom field and are not done.
+	//
+	// jql is an optional JQL clause further restricting the returned issues.
+	// Returned issues only contain their summary, status, labels, comments and GitHub custom fields.
+	ListOpenIssuesWithGithubID(jql string) ([]jiralib.Issue, error)
+
+	// AddIssueLabel adds a label to an issue, existing labels are kept
+	//
+	// JIRA API docs: https://docs.atlassian.com/jira/REST/latest/#api/2/issue-editIssue
+	AddIssueLabel(issueKeyOrID, label string) error
+
 	// UpdateIssue will update a given issue.
 	//
 	// JIRA API docs: https://developer.atlassian.com/cloud/jira/platform/rest/v3/?utm_source=/cloud/jira/platform/rest/&utm_medium=302#api-api-3-version-id-put
@@ -55,11 +67,14 @@ type API interface {
 	// CreateIssue creates an issue or a sub-task from a JSON representation.
 	//
 	// JIRA API docs: https://docs.atlassian.com/jira/REST/latest/#api/2/issue-createIssues
-	CreateIssue(issueType, summary, description, epicKey string, components []string, sprint *int, githubID int64, githubNumber int, githubLabels []string, githubStatus string) (*jiralib.Issue, error)
+	CreateIssue(options CreateIssueOptions) (*jiralib.Issue, error)
 
 	// GetCustomFieldID returns a custom field ID based on its name. If not found an empty string is returned.
 	GetCustomFieldID(name string) string
 
+	// IsIssueTypeEstimable checks if issues of the given type support estimation on the board
+	IsIssueTypeEstimable(issueTypeName string) bool
+
 	// MoveToBacklog moves a list of issues identified by there issue keys to the backlog.
 	// This operation is equivalent to remove future and active sprints from a given set of issues.
 	//
@@ -89,6 +104,33 @@ type API interface {
 	// TransitionIssue execute transition identified by the given name to the issue
 	TransitionIssue(issueKeyOrID, transitionName string) error
 
+	// TransitionIssueWithFields execute transition identified by the given name to the issue setting the given
+	// fields of the transition screen, such as the resolution
+	//
+	// JIRA API docs: https://docs.atlassian.com/jira/REST/latest/#api/2/issue-doTransition
+	TransitionIssueWithFields(issueKeyOrID, transitionName string, fields map[string]interface{}) error
+
+	// GetIssueTransitions returns the list of transitions available for the given issue in its current status
+	//
+	// JIRA API docs: https://docs.atlassian.com/jira/REST/latest/#api/2/issue-getTransitions
+	GetIssueTransitions(issueKeyOrID string) ([]jiralib.Transition, error)
+
+	// GetIssueStatus returns the current status of the given issue
+	//
+	// JIRA API docs: https://docs.atlassian.com/jira/REST/latest/#api/2/issue-getIssue
+	GetIssueStatus(issueKeyOrID string) (*jiralib.Status, error)
+
+	// GetLastStatusChange returns the date of the last status change of the given issue, a zero time is returned
+	// if its status never changed
+	//
+	// JIRA API docs: https://docs.atlassian.com/jira/REST/latest/#api/2/issue-getIssue
+	GetLastStatusChange(issueKeyOrID string) (time.Time, error)
+
+	// RankIssues moves the given issues before or after a given issue, only one of rankBeforeIssue and rankAfterIssue should be set.
+	//
+	// JIRA API docs: https://developer.atlassian.com/cloud/jira/software/rest/#api-rest-agile-1-0-issue-rank-put
+	RankIssues(issuesKeys []string, rankBeforeIssue, rankAfterIssue string) error
+
 	// AddComment adds a new comment to issueID.
 	//
 	// JIRA API docs: https://docs.atlassian.com/jira/REST/latest/#api/2/issue-addComment
@@ -98,6 +140,25 @@ type API interface {
 	//
 	// JIRA API docs: https://docs.atlassian.com/jira/REST/cloud/#api/2/issue/{issueIdOrKey}/comment-updateComment
 	UpdateComment(issueKeyOrID, commentID, body string) (*jiralib.Comment, error)
+
+	// AddAttachment uploads the given content as an attachment named filename to an issue.
+	//
+	// JIRA API docs: https://docs.atlassian.com/jira/REST/latest/#api/2/issue/{issueIdOrKey}/attachments-addAttachment
+	AddAttachment(issueKeyOrID, filename string, content io.Reader) (*jiralib.Attachment, error)
+
+	// FindUserByEmail returns the active Jira user having the given email address.
+	//
+	// The returned user may be nil if none or several users were found.
+	//
+	// JIRA API docs: https://docs.atlassian.com/jira/REST/cloud/#api/2/user-findUsers
+	FindUserByEmail(email string) (*jiralib.User, error)
+
+	// GetUserID returns the identifier of the given user, its account ID or its username depending on the Jira deployment.
+	GetUserID(user *jiralib.User) string
+
+	// NewUserFromID returns a user reference that could be used to set users fields of issues from an identifier
+	// as returned by GetUserID
+	NewUserFromID(id string) *jiralib.User
 }
 
 // Version represents a Jira Version
@@ -0,0 +1,11 @@
+package jira
+
+import (
+	"testing"
+)
+
+func TestAPIImplementation(t *testing.T) {
+	var _ API = (*Client)(nil)
+	var _ API = (*RecordingClient)(nil)
+	var _ API = (*InstrumentedClient)(nil)
+}
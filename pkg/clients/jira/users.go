@@ -0,0 +1,72 @@
+package jira
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+
+	jiralib "github.com/andygrunwald/go-jira"
+	"github.com/pkg/errors"
+)
+
+// FindUserByEmail returns the active Jira user having the given email address.
+//
+// Jira Cloud may hide email addresses of users, in this case a single matching user is considered as found.
+// The returned user may be nil if none or several users were found.
+//
+// JIRA API docs: https://docs.atlassian.com/jira/REST/cloud/#api/2/user-findUsers
+func (c *Client) FindUserByEmail(email string) (*jiralib.User, error) {
+	param := "query"
+	if c.UseUsernames {
+		// Jira Server matches usernames, names and email addresses with the username parameter
+		param = "username"
+	}
+	req, err := c.JiraClient.NewRequest("GET", fmt.Sprintf("/rest/api/2/user/search?%s=%s", param, url.QueryEscape(email)), nil)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to search Jira user with email %q", email)
+	}
+	var users []jiralib.User
+	resp, err := c.JiraClient.Do(req, &users)
+	if err != nil {
+		err = jiralib.NewJiraError(resp, err)
+		return nil, errors.Wrapf(err, "failed to search Jira user with email %q", email)
+	}
+	var candidates []jiralib.User
+	for _, u := range users {
+		if !u.Active {
+			continue
+		}
+		if strings.EqualFold(u.EmailAddress, email) {
+			return &u, nil
+		}
+		if u.EmailAddress == "" {
+			candidates = append(candidates, u)
+		}
+	}
+	if len(candidates) == 1 {
+		return &candidates[0], nil
+	}
+	return nil, nil
+}
+
+// GetUserID returns the identifier of the given user, its account ID or its username if UseUsernames is set.
+//
+// An empty string is returned for a nil user.
+func (c *Client) GetUserID(user *jiralib.User) string {
+	if user == nil {
+		return ""
+	}
+	if c.UseUsernames {
+		return user.Name
+	}
+	return user.AccountID
+}
+
+// NewUserFromID returns a user reference that could be used to set users fields of issues from an identifier
+// as returned by GetUserID
+func (c *Client) NewUserFromID(id string) *jiralib.User {
+	if c.UseUsernames {
+		return &jiralib.User{Name: id}
+	}
+	return &jiralib.User{AccountID: id}
+}
@@ -0,0 +1,240 @@
+package retry
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"io/ioutil"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	defaultMaxRetries = 5
+	defaultMinBackoff = time.Second
+	defaultMaxBackoff = time.Minute
+	defaultMaxDelay   = 15 * time.Minute
+)
+
+// Transport is an http.RoundTripper retrying requests that failed due to rate limiting or server errors.
+//
+// Delays between retries honor the Retry-After and X-RateLimit-Reset headers (used by both GitHub and ZenHub),
+// otherwise an exponential backoff with jitter is used. Waiting for a retry is interrupted if the request context is cancelled.
+type Transport struct {
+	// Base is the transport used to actually perform requests, http.DefaultTransport is used if nil
+	Base http.RoundTripper
+	// MaxRetries is the maximum number of retries of a single request
+	MaxRetries int
+	// MinBackoff and MaxBackoff bounds the exponential backoff delay
+	MinBackoff time.Duration
+	MaxBackoff time.Duration
+	// MaxDelay is the maximum delay to wait for a retry, responses requiring to wait longer are returned as is
+	MaxDelay time.Duration
+	// AttemptTimeout is the timeout of a single attempt, 0 means no timeout
+	AttemptTimeout time.Duration
+}
+
+// NewTransport creates a Transport with default settings on top of the given base transport
+func NewTransport(base http.RoundTripper) *Transport {
+	return &Transport{
+		Base:       base,
+		MaxRetries: defaultMaxRetries,
+		MinBackoff: defaultMinBackoff,
+		MaxBackoff: defaultMaxBackoff,
+		MaxDelay:   defaultMaxDelay,
+	}
+}
+
+func (t *Transport) base() http.RoundTripper {
+	if t.Base != nil {
+		return t.Base
+	}
+	return http.DefaultTransport
+}
+
+// RoundTrip executes a single HTTP transaction, retrying it when needed
+func (t *Transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	getBody := req.GetBody
+	if req.Body != nil && req.Body != http.NoBody && getBody == nil {
+		// Buffer the body to be able to replay it
+		b, err := ioutil.ReadAll(req.Body)
+		req.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+		getBody = func() (io.ReadCloser, error) {
+			return ioutil.NopCloser(bytes.NewReader(b)), nil
+		}
+	}
+
+	ctx := req.Context()
+	for attempt := 0; ; attempt++ {
+		attemptReq := req.Clone(ctx)
+		if getBody != nil {
+			body, err := getBody()
+			if err != nil {
+				return nil, err
+			}
+			attemptReq.Body = body
+		}
+		resp, err := t.roundTripAttempt(attemptReq)
+
+		if attempt >= t.MaxRetries || ctx.Err() != nil {
+			return resp, err
+		}
+		var delay time.Duration
+		if err != nil {
+			if !isIdempotent(req.Method) {
+				return resp, err
+			}
+			delay = t.backoff(attempt)
+		} else {
+			var retry bool
+			retry, delay = t.retryDelay(req.Method, resp, attempt)
+			if !retry {
+				return resp, err
+			}
+			if delay > t.MaxDelay {
+				logrus.WithField("method", req.Method).WithField("url", req.URL.Host+req.URL.Path).Warnf("Not retrying %s, retry delay %v is too long", resp.Status, delay)
+				return resp, err
+			}
+			// Drain body to reuse the connection
+			io.Copy(ioutil.Discard, resp.Body)
+			resp.Body.Close()
+		}
+
+		logrus.WithField("method", req.Method).WithField("url", req.URL.Host+req.URL.Path).Infof("Retrying in %v (attempt %d/%d)", delay, attempt+1, t.MaxRetries)
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil, ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
+
+func (t *Transport) roundTripAttempt(req *http.Request) (*http.Response, error) {
+	if t.AttemptTimeout <= 0 {
+		return t.base().RoundTrip(req)
+	}
+	ctx, cancel := context.WithTimeout(req.Context(), t.AttemptTimeout)
+	resp, err := t.base().RoundTrip(req.WithContext(ctx))
+	if err != nil {
+		cancel()
+		return resp, err
+	}
+	// The attempt context should live until the body is read
+	resp.Body = &cancelOnCloseBody{ReadCloser: resp.Body, cancel: cancel}
+	return resp, nil
+}
+
+type cancelOnCloseBody struct {
+	io.ReadCloser
+	cancel context.CancelFunc
+}
+
+func (b *cancelOnCloseBody) Close() error {
+	err := b.ReadCloser.Close()
+	b.cancel()
+	return err
+}
+
+// retryDelay checks if a response should be retried and after which delay.
+//
+// Server errors are only retried for idempotent methods as the request may have been processed, for instance
+// if a gateway timed out, while rate limited requests are known not to have been processed.
+func (t *Transport) retryDelay(method string, resp *http.Response, attempt int) (bool, time.Duration) {
+	switch {
+	case resp.StatusCode == http.StatusTooManyRequests:
+	case resp.StatusCode == http.StatusForbidden && isRateLimited(resp):
+	case resp.StatusCode >= 500 && resp.StatusCode != http.StatusNotImplemented && isIdempotent(method):
+	default:
+		return false, 0
+	}
+
+	if delay, ok := parseRetryAfter(resp.Header.Get("Retry-After")); ok {
+		return true, delay
+	}
+	if resp.StatusCode < 500 {
+		// Rate limited, wait for the limit reset
+		if delay, ok := parseRateLimitReset(resp.Header.Get("X-RateLimit-Reset")); ok {
+			return true, delay
+		}
+	}
+	return true, t.backoff(attempt)
+}
+
+// isRateLimited checks if a forbidden response is due to rate limiting.
+//
+// GitHub uses X-RateLimit-Remaining, ZenHub X-RateLimit-Used and X-RateLimit-Limit, both may use Retry-After.
+func isRateLimited(resp *http.Response) bool {
+	if resp.Header.Get("Retry-After") != "" || resp.Header.Get("X-RateLimit-Remaining") == "0" {
+		return true
+	}
+	used, err := strconv.Atoi(resp.Header.Get("X-RateLimit-Used"))
+	if err != nil {
+		return false
+	}
+	limit, err := strconv.Atoi(resp.Header.Get("X-RateLimit-Limit"))
+	return err == nil && used >= limit
+}
+
+func parseRetryAfter(value string) (time.Duration, bool) {
+	if value == "" {
+		return 0, false
+	}
+	if seconds, err := strconv.Atoi(value); err == nil {
+		return time.Duration(seconds) * time.Second, true
+	}
+	if date, err := http.ParseTime(value); err == nil {
+		return positive(time.Until(date)), true
+	}
+	return 0, false
+}
+
+// parseRateLimitReset parses a reset date expressed as UTC epoch seconds
+func parseRateLimitReset(value string) (time.Duration, bool) {
+	epoch, err := strconv.ParseInt(value, 10, 64)
+	if err != nil {
+		return 0, false
+	}
+	// Add a second to be sure that the limit is actually reset
+	return positive(time.Until(time.Unix(epoch, 0))) + time.Second, true
+}
+
+func positive(d time.Duration) time.Duration {
+	if d < 0 {
+		return 0
+	}
+	return d
+}
+
+// backoff returns an exponential backoff delay with jitter for the given attempt
+func (t *Transport) backoff(attempt int) time.Duration {
+	delay := t.MaxBackoff
+	if attempt < 32 {
+		delay = t.MinBackoff << uint(attempt)
+	}
+	if delay > t.MaxBackoff || delay <= 0 {
+		delay = t.MaxBackoff
+	}
+	if delay <= 0 {
+		return 0
+	}
+	// Full jitter between half and full delay
+	return delay/2 + time.Duration(rand.Int63n(int64(delay)/2+1))
+}
+
+func isIdempotent(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodPut, http.MethodDelete:
+		return true
+	default:
+		return false
+	}
+}
@@ -0,0 +1,106 @@
+package retry
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func newTestTransport() *Transport {
+	t := NewTransport(nil)
+	t.MinBackoff = time.Millisecond
+	t.MaxBackoff = 5 * time.Millisecond
+	t.MaxRetries = 3
+	return t
+}
+
+func TestTransportRetries(t *testing.T) {
+	tests := []struct {
+		name          string
+		method        string
+		status        int
+		headers       map[string]string
+		expectedCalls int32
+	}{
+		{"Success", http.MethodGet, http.StatusOK, nil, 1},
+		{"NotFound", http.MethodGet, http.StatusNotFound, nil, 1},
+		{"ForbiddenNotRateLimited", http.MethodGet, http.StatusForbidden, nil, 1},
+		{"ServerError", http.MethodGet, http.StatusBadGateway, nil, 4},
+		{"ServerErrorNotIdempotent", http.MethodPost, http.StatusGatewayTimeout, nil, 1},
+		{"TooManyRequests", http.MethodGet, http.StatusTooManyRequests, map[string]string{"Retry-After": "0"}, 4},
+		{"TooManyRequestsNotIdempotent", http.MethodPost, http.StatusTooManyRequests, map[string]string{"Retry-After": "0"}, 4},
+		{"GitHubRateLimited", http.MethodGet, http.StatusForbidden, map[string]string{"X-RateLimit-Remaining": "0", "X-RateLimit-Reset": "0"}, 4},
+		{"ZenHubRateLimited", http.MethodPost, http.StatusForbidden, map[string]string{"X-RateLimit-Used": "100", "X-RateLimit-Limit": "100"}, 4},
+		{"RetryAfterTooLong", http.MethodGet, http.StatusTooManyRequests, map[string]string{"Retry-After": "3600"}, 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var calls int32
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				atomic.AddInt32(&calls, 1)
+				for k, v := range tt.headers {
+					w.Header().Set(k, v)
+				}
+				w.WriteHeader(tt.status)
+			}))
+			defer server.Close()
+			transport := newTestTransport()
+			transport.MaxDelay = time.Minute
+			req, _ := http.NewRequest(tt.method, server.URL, nil)
+			resp, err := (&http.Client{Transport: transport}).Do(req)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			resp.Body.Close()
+			if resp.StatusCode != tt.status {
+				t.Errorf("unexpected status %d, expecting %d", resp.StatusCode, tt.status)
+			}
+			if calls != tt.expectedCalls {
+				t.Errorf("unexpected number of calls %d, expecting %d", calls, tt.expectedCalls)
+			}
+		})
+	}
+}
+
+func TestTransportReplaysBody(t *testing.T) {
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		b, _ := ioutil.ReadAll(r.Body)
+		if string(b) != "payload" {
+			t.Errorf("unexpected body %q", string(b))
+		}
+		if atomic.AddInt32(&calls, 1) == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+	}))
+	defer server.Close()
+	req, _ := http.NewRequest(http.MethodPut, server.URL, ioutil.NopCloser(strings.NewReader("payload")))
+	resp, err := (&http.Client{Transport: newTestTransport()}).Do(req)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK || calls != 2 {
+		t.Errorf("unexpected status %d after %d calls", resp.StatusCode, calls)
+	}
+}
+
+func TestTransportContextCancellation(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Retry-After", "30")
+		w.WriteHeader(http.StatusTooManyRequests)
+	}))
+	defer server.Close()
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	req, _ := http.NewRequest(http.MethodGet, server.URL, nil)
+	_, err := (&http.Client{Transport: newTestTransport()}).Do(req.WithContext(ctx))
+	if err == nil {
+		t.Fatal("expecting an error on context cancellation")
+	}
+}
@@ -10,9 +10,16 @@ import (
 )
 
 // GetBoard retrieves ZenHub Board
+//
+// The board of the configured Workspace is retrieved if any, otherwise the board of the default workspace.
+//
+// ZenHub API docs: https://github.com/ZenHubIO/API#get-a-zenhub-board-for-a-repository
 func (c *Client) GetBoard() (*Board, error) {
-
-	req, err := http.NewRequest("GET", c.urlFor(fmt.Sprintf("/p1/repositories/%d/board", c.Repository)).String(), nil)
+	path := fmt.Sprintf("/p1/repositories/%d/board", c.Repository)
+	if c.Workspace != "" {
+		path = fmt.Sprintf("/p2/workspaces/%s/repositories/%d/board", c.Workspace, c.Repository)
+	}
+	req, err := http.NewRequest("GET", c.urlFor(path).String(), nil)
 	if err != nil {
 		return nil, errors.Wrap(err, "Failed to create zenhub request to get board")
 	}
@@ -1,15 +1,19 @@
 package zenhub
 
 import (
-	"fmt"
-	"log"
+	"io"
+	"io/ioutil"
 	"net/http"
 	"net/http/httputil"
 	"net/url"
+	"strings"
 	"time"
 
 	"github.com/google/go-github/v24/github"
 	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+
+	"github.com/ystia/zenhub-jira-sync/pkg/clients/retry"
 )
 
 // API abstracts JIRA API to things needed by this project
@@ -24,19 +28,33 @@ type API interface {
 	DecorateGHMilestone(ghMilestone *github.Milestone) (*Milestone, error)
 	// GetReleasesReports returns releases reports for the associated repository.
 	GetReleasesReports() ([]*ReleaseReport, error)
-	// GetIssuesForReleaseReport returns issues related to a release report
+	// GetIssuesForReleaseReport returns issues related to a release report, including issues of other repositories
 	GetIssuesForReleaseReport(releaseID string) ([]IssueID, error)
 	// GetBoard retrieves ZenHub Board
+	//
+	// The board of the configured Workspace is retrieved if any, otherwise the board of the default workspace.
 	GetBoard() (*Board, error)
-	// GetEpics returns Epics defined in this repository
+	// GetWorkspaces returns the workspaces containing this repository
 	//
+	// ZenHub API docs: https://github.com/ZenHubIO/API#get-zenhub-workspaces-for-a-repository
+	GetWorkspaces() ([]*Workspace, error)
+	// GetEpics returns Epics of this repository
+	//
+	// Epics may be defined in another repository of the workspace, in this case their RepoID differs from this repository.
 	// Github associated issue are not initialized, neither in epics nor in issues
 	GetEpics() ([]*Epic, error)
-	// GetEpic returns a single Epic
+	// GetEpic returns a single Epic of this repository
 	//
-	// Associated issues are filtered to only those that are from the same repository
+	// Associated issues may be from other repositories, their RepoID is always set.
 	// Github associated issue are not initialized, neither in epics nor in issues
 	GetEpic(epicNumber int) (*Epic, error)
+	// GetIssue returns ZenHub data of a single issue
+	//
+	// The pipeline of the issue is the one of the configured Workspace if any.
+	// Github associated issue is not initialized
+	GetIssue(issueNumber int) (*Issue, error)
+	// DecorateGithubIssue transforms a GitHub Issue into a ZenHub Issue.
+	DecorateGithubIssue(ghIssue *github.Issue) (*Issue, error)
 }
 
 // Client manages communication with the ZenHub API.
@@ -45,15 +63,28 @@ type Client struct {
 	AuthToken  string
 	UserAgent  string
 	Repository int64
-	Verbose    bool
+	// Workspace is the ID of the ZenHub workspace used to retrieve the board, if empty the default workspace is used
+	Workspace string
+	Verbose   bool
+	// HTTPClient is used to perform requests, if nil a client retrying rate limited requests is created
+	HTTPClient *http.Client
 }
 
 const (
 	defaultBaseURL    = "https://api.zenhub.io/"
 	defaultUserAgent  = "zenhub-client"
 	apiRequestTimeout = 30 * time.Second
+	maxErrorBodySize  = 1024
 )
 
+// NewHTTPClient returns an HTTP client suitable for the ZenHub API, it retries rate limited requests
+// sent using the given base transport (http.DefaultTransport if nil)
+func NewHTTPClient(base http.RoundTripper) *http.Client {
+	transport := retry.NewTransport(base)
+	transport.AttemptTimeout = apiRequestTimeout
+	return &http.Client{Transport: transport}
+}
+
 // NewClient creates a client with the given token on the given repository,
 // will use defaults for everything else.
 //
@@ -66,6 +97,7 @@ func NewClient(authToken string, repo int64) *Client {
 		UserAgent:  defaultUserAgent,
 		Verbose:    false,
 		Repository: repo,
+		HTTPClient: NewHTTPClient(nil),
 	}
 }
 
@@ -82,6 +114,7 @@ func NewClientWithOptions(authToken string, repo int64, baseurl string, verbose
 		UserAgent:  defaultUserAgent,
 		Repository: repo,
 		Verbose:    verbose,
+		HTTPClient: NewHTTPClient(nil),
 	}, nil
 }
 
@@ -118,12 +151,14 @@ func (c *Client) Request(req *http.Request) (resp *http.Response, err error) {
 	if c.Verbose {
 		dump, err := httputil.DumpRequest(req, true)
 		if err == nil {
-			log.Printf("%s", dump)
+			logrus.Infof("%s", dump)
 		}
 	}
 
-	client := &http.Client{}
-	client.Timeout = apiRequestTimeout
+	client := c.HTTPClient
+	if client == nil {
+		client = NewHTTPClient(nil)
+	}
 	resp, err = client.Do(req)
 
 	if err != nil {
@@ -132,12 +167,14 @@ func (c *Client) Request(req *http.Request) (resp *http.Response, err error) {
 	if c.Verbose {
 		dump, err := httputil.DumpResponse(resp, true)
 		if err == nil {
-			log.Printf("%s", dump)
+			logrus.Infof("%s", dump)
 		}
 	}
 
 	if resp.StatusCode/100 != 2 {
-		return nil, errors.New(fmt.Sprintf("request failed. status code is %d", resp.StatusCode))
+		defer resp.Body.Close()
+		body, _ := ioutil.ReadAll(io.LimitReader(resp.Body, maxErrorBodySize))
+		return nil, errors.Errorf("request %s %s failed. status code is %d: %s", req.Method, req.URL.Path, resp.StatusCode, strings.TrimSpace(string(body)))
 	}
 
 	return resp, nil
@@ -9,8 +9,9 @@ import (
 	"github.com/pkg/errors"
 )
 
-// GetEpics returns Epics defined in this repository
+// GetEpics returns Epics of this repository
 //
+// Epics may be defined in another repository of the workspace, in this case their RepoID differs from this repository.
 // Github associated issue are not initialized, neither in epics nor in issues
 func (c *Client) GetEpics() ([]*Epic, error) {
 	req, err := http.NewRequest("GET", c.urlFor(fmt.Sprintf("/p1/repositories/%d/epics", c.Repository)).String(), nil)
@@ -43,8 +44,11 @@ func (c *Client) GetEpics() ([]*Epic, error) {
 	}
 	result := make([]*Epic, 0, len(el.EpicIssues))
 	for _, ep := range el.EpicIssues {
-		epic, err := c.GetEpic(ep.IssueNumber)
-		epic.RepoID = ep.RepoID
+		repoID := c.Repository
+		if ep.RepoID != nil {
+			repoID = *ep.RepoID
+		}
+		epic, err := c.getEpic(repoID, ep.IssueNumber)
 		if err != nil {
 			return nil, err
 		}
@@ -53,12 +57,16 @@ func (c *Client) GetEpics() ([]*Epic, error) {
 	return result, nil
 }
 
-// GetEpic returns a single Epic
+// GetEpic returns a single Epic of this repository
 //
-// Associated issues are filtered to only those that are from the same repository
+// Associated issues may be from other repositories, their RepoID is always set.
 // Github associated issue are not initialized, neither in epics nor in issues
 func (c *Client) GetEpic(epicNumber int) (*Epic, error) {
-	req, err := http.NewRequest("GET", c.urlFor(fmt.Sprintf("/p1/repositories/%d/epics/%d", c.Repository, epicNumber)).String(), nil)
+	return c.getEpic(c.Repository, epicNumber)
+}
+
+func (c *Client) getEpic(repoID int64, epicNumber int) (*Epic, error) {
+	req, err := http.NewRequest("GET", c.urlFor(fmt.Sprintf("/p1/repositories/%d/epics/%d", repoID, epicNumber)).String(), nil)
 	if err != nil {
 		return nil, errors.Wrap(err, "Failed to create zenhub request to get epic data")
 	}
@@ -80,15 +88,15 @@ func (c *Client) GetEpic(epicNumber int) (*Epic, error) {
 		return nil, errors.Wrap(err, "Failed to read zenhub response to get epic data")
 	}
 
-	filteredIssues := epic.Issues[:0]
-	for _, i := range epic.Issues {
-		// filter issues that are not from the same repository
-		// Also filter of epics
-		if i.RepoID == nil || *i.RepoID == c.Repository {
-			filteredIssues = append(filteredIssues, i)
+	for i := range epic.Issues {
+		if epic.Issues[i].RepoID == nil {
+			epic.Issues[i].RepoID = &repoID
 		}
 	}
-	epic.Issues = filteredIssues
+	if epic.Issue == nil {
+		epic.Issue = new(Issue)
+	}
+	epic.RepoID = &repoID
 	epic.IssueNumber = &epicNumber
 	epic.IsEpic = true
 	return epic, nil
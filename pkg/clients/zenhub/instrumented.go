@@ -0,0 +1,100 @@
+package zenhub
+
+import (
+	"time"
+
+	"github.com/google/go-github/v24/github"
+)
+
+// InstrumentedClient wraps an API and reports each call with its duration and error to the OnCall function.
+type InstrumentedClient struct {
+	API
+	// OnCall is called after each API call with the API method name, the call duration and the returned error
+	OnCall func(method string, duration time.Duration, err error)
+}
+
+func (c *InstrumentedClient) onCall(method string, start time.Time, err error) {
+	if c.OnCall != nil {
+		c.OnCall(method, time.Since(start), err)
+	}
+}
+
+// GetMilestoneStartDate reports the call
+func (c *InstrumentedClient) GetMilestoneStartDate(milestoneNumber int) (*time.Time, error) {
+	start := time.Now()
+	result, err := c.API.GetMilestoneStartDate(milestoneNumber)
+	c.onCall("GetMilestoneStartDate", start, err)
+	return result, err
+}
+
+// DecorateGHMilestone reports the call
+func (c *InstrumentedClient) DecorateGHMilestone(ghMilestone *github.Milestone) (*Milestone, error) {
+	start := time.Now()
+	result, err := c.API.DecorateGHMilestone(ghMilestone)
+	c.onCall("DecorateGHMilestone", start, err)
+	return result, err
+}
+
+// GetReleasesReports reports the call
+func (c *InstrumentedClient) GetReleasesReports() ([]*ReleaseReport, error) {
+	start := time.Now()
+	result, err := c.API.GetReleasesReports()
+	c.onCall("GetReleasesReports", start, err)
+	return result, err
+}
+
+// GetIssuesForReleaseReport reports the call
+func (c *InstrumentedClient) GetIssuesForReleaseReport(releaseID string) ([]IssueID, error) {
+	start := time.Now()
+	result, err := c.API.GetIssuesForReleaseReport(releaseID)
+	c.onCall("GetIssuesForReleaseReport", start, err)
+	return result, err
+}
+
+// GetBoard reports the call
+func (c *InstrumentedClient) GetBoard() (*Board, error) {
+	start := time.Now()
+	result, err := c.API.GetBoard()
+	c.onCall("GetBoard", start, err)
+	return result, err
+}
+
+// GetWorkspaces reports the call
+func (c *InstrumentedClient) GetWorkspaces() ([]*Workspace, error) {
+	start := time.Now()
+	result, err := c.API.GetWorkspaces()
+	c.onCall("GetWorkspaces", start, err)
+	return result, err
+}
+
+// GetEpics reports the call
+func (c *InstrumentedClient) GetEpics() ([]*Epic, error) {
+	start := time.Now()
+	result, err := c.API.GetEpics()
+	c.onCall("GetEpics", start, err)
+	return result, err
+}
+
+// GetEpic reports the call
+func (c *InstrumentedClient) GetEpic(epicNumber int) (*Epic, error) {
+	start := time.Now()
+	result, err := c.API.GetEpic(epicNumber)
+	c.onCall("GetEpic", start, err)
+	return result, err
+}
+
+// GetIssue reports the call
+func (c *InstrumentedClient) GetIssue(issueNumber int) (*Issue, error) {
+	start := time.Now()
+	result, err := c.API.GetIssue(issueNumber)
+	c.onCall("GetIssue", start, err)
+	return result, err
+}
+
+// DecorateGithubIssue reports the call
+func (c *InstrumentedClient) DecorateGithubIssue(ghIssue *github.Issue) (*Issue, error) {
+	start := time.Now()
+	result, err := c.API.DecorateGithubIssue(ghIssue)
+	c.onCall("DecorateGithubIssue", start, err)
+	return result, err
+}
@@ -10,7 +10,10 @@ import (
 	"github.com/pkg/errors"
 )
 
-// GetIssue
+// GetIssue returns ZenHub data of a single issue
+//
+// The pipeline of the issue is the one of the configured Workspace if any.
+// Github associated issue is not initialized
 func (c *Client) GetIssue(issueNumber int) (*Issue, error) {
 	req, err := http.NewRequest("GET", c.urlFor(fmt.Sprintf("/p1/repositories/%d/issues/%d", c.Repository, issueNumber)).String(), nil)
 	if err != nil {
@@ -28,17 +31,39 @@ func (c *Client) GetIssue(issueNumber int) (*Issue, error) {
 		return nil, errors.Wrap(err, "Failed to read zenhub response to get issue data")
 	}
 
-	issue := new(Issue)
-	err = json.Unmarshal(body, issue)
-
-	return issue, errors.Wrap(err, "Failed to read zenhub response to get issue data")
+	var data struct {
+		Issue
+		Pipelines []struct {
+			Name        string `json:"name"`
+			WorkspaceID string `json:"workspace_id"`
+		} `json:"pipelines,omitempty"`
+	}
+	err = json.Unmarshal(body, &data)
+	if err != nil {
+		return nil, errors.Wrap(err, "Failed to read zenhub response to get issue data")
+	}
+	issue := &data.Issue
+	if c.Workspace != "" {
+		// The pipeline field is the one of the default workspace
+		issue.Pipeline = nil
+		for _, p := range data.Pipelines {
+			if p.WorkspaceID == c.Workspace {
+				issue.Pipeline = &IssueDataPipeline{Name: p.Name}
+				break
+			}
+		}
+	}
+	return issue, nil
 }
 
+// DecorateGithubIssue transforms a GitHub Issue into a ZenHub Issue.
 func (c *Client) DecorateGithubIssue(ghIssue *gh.Issue) (*Issue, error) {
 	issue, err := c.GetIssue(*ghIssue.Number)
 	if err != nil {
 		return nil, err
 	}
 	issue.Issue = ghIssue
+	issue.IssueNumber = ghIssue.Number
+	issue.RepoID = &c.Repository
 	return issue, nil
 }
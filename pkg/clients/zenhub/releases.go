@@ -53,14 +53,5 @@ func (c *Client) GetIssuesForReleaseReport(releaseID string) ([]IssueID, error)
 
 	var res []IssueID
 	err = json.Unmarshal(body, &res)
-
-	filteredIssues := res[:0]
-	for _, i := range res {
-		// filter issues that are not from the same repository
-		if i.RepoID == nil || *i.RepoID == c.Repository {
-			filteredIssues = append(filteredIssues, i)
-		}
-	}
-
-	return filteredIssues, errors.Wrapf(err, "Failed to read zenhub response to get issues for release report %q", releaseID)
+	return res, errors.Wrapf(err, "Failed to read zenhub response to get issues for release report %q", releaseID)
 }
@@ -54,6 +54,14 @@ type IssueDataPipeline struct {
 	Name string `json:"name,omitempty"`
 }
 
+// Workspace represents a ZenHub Workspace
+type Workspace struct {
+	ID           string  `json:"id"`
+	Name         string  `json:"name"`
+	Description  string  `json:"description,omitempty"`
+	Repositories []int64 `json:"repositories,omitempty"`
+}
+
 // Board represents the root data structure of the Board API
 type Board struct {
 	Pipelines []BoardPipeline `json:"pipelines,omitempty"`
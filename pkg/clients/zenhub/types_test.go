@@ -0,0 +1,10 @@
+package zenhub
+
+import (
+	"testing"
+)
+
+func TestAPIImplementation(t *testing.T) {
+	var _ API = (*Client)(nil)
+	var _ API = (*InstrumentedClient)(nil)
+}
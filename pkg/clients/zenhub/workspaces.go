@@ -0,0 +1,35 @@
+package zenhub
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+
+	"github.com/pkg/errors"
+)
+
+// GetWorkspaces returns the workspaces containing this repository
+//
+// ZenHub API docs: https://github.com/ZenHubIO/API#get-zenhub-workspaces-for-a-repository
+func (c *Client) GetWorkspaces() ([]*Workspace, error) {
+	req, err := http.NewRequest("GET", c.urlFor(fmt.Sprintf("/p2/repositories/%d/workspaces", c.Repository)).String(), nil)
+	if err != nil {
+		return nil, errors.Wrap(err, "Failed to create zenhub request to get workspaces")
+	}
+
+	resp, err := c.Request(req)
+	if err != nil {
+		return nil, errors.Wrap(err, "Failed to execute zenhub request to get workspaces")
+	}
+	defer resp.Body.Close()
+
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, errors.Wrap(err, "Failed to read zenhub response to get workspaces")
+	}
+
+	var workspaces []*Workspace
+	err = json.Unmarshal(body, &workspaces)
+	return workspaces, errors.Wrap(err, "Failed to read zenhub response to get workspaces")
+}
@@ -7,18 +7,35 @@ import (
 
 	jiralib "github.com/andygrunwald/go-jira"
 	gh "github.com/google/go-github/v24/github"
+
+	"github.com/ystia/zenhub-jira-sync/pkg/markup"
 )
 
-func (s *Sync) compareComments(ctx context.Context, ghIssue *gh.Issue, jiraIssue *jiralib.Issue) error {
+const (
+	// ghCommentMarkerPrefix starts the header of Jira comments copied from GitHub comments
+	ghCommentMarkerPrefix = "GitHub Comment: ID: ["
+	// jiraCommentMarkerPrefix starts the header of GitHub comments copied from Jira comments
+	jiraCommentMarkerPrefix = "Jira Comment: ID: ["
+	// syncCommentMarker ends Jira comments written by the synchronization that are not copies of GitHub comments
+	syncCommentMarker = "~Written by zenhub-jira-sync~"
+)
 
+// getGithubComments returns comments of the given GitHub issue
+func (s *Sync) getGithubComments(ctx context.Context, ghIssue *gh.Issue) ([]*gh.IssueComment, error) {
 	if ghIssue.GetComments() == 0 {
 		// no comments
-		return nil
+		return nil, nil
 	}
+	return s.GithubClient.GetIssueComments(ctx, ghIssue.GetNumber())
+}
+
+// compareComments copies GitHub comments to the Jira issue, attachments are the names of mirrored Jira attachments
+// indexed by GitHub URL. It returns true if Jira comments were created or updated.
+func (s *Sync) compareComments(ctx context.Context, ghIssue *gh.Issue, ghComments []*gh.IssueComment, jiraIssue *jiralib.Issue, attachments map[string]string) (bool, error) {
 
-	ghComments, err := s.GithubClient.GetIssueComments(ctx, ghIssue.GetNumber())
-	if err != nil {
-		return err
+	if len(ghComments) == 0 && !s.JiraCommentsToGithub {
+		// no comments
+		return false, nil
 	}
 
 	var jiraComments []*jiralib.Comment
@@ -26,13 +43,54 @@ func (s *Sync) compareComments(ctx context.Context, ghIssue *gh.Issue, jiraIssue
 		jiraComments = jiraIssue.Fields.Comments.Comments
 	}
 
+	var commented bool
 	for _, ghc := range ghComments {
+		if strings.Contains(ghc.GetBody(), jiraCommentMarkerPrefix) {
+			// This comment comes from Jira, do not copy it back
+			continue
+		}
 		var commentFound bool
 		for _, jc := range jiraComments {
-			if strings.Contains(jc.Body, fmt.Sprintf("GitHub Comment: ID: [%d]", ghc.GetID())) {
+			if strings.Contains(jc.Body, fmt.Sprintf("%s%d]", ghCommentMarkerPrefix, ghc.GetID())) {
+				commentFound = true
+				if !markup.Contains(jc.Body, markup.ToJiraWikiWithAttachments(ghc.GetBody(), attachments)) {
+					err := s.updateJiraComment(ghc, jiraIssue, jc, attachments)
+					if err != nil {
+						return commented, err
+					}
+					commented = true
+				}
+				break
+			}
+		}
+		if !commentFound {
+			err := s.createJiraComment(ghc, jiraIssue, attachments)
+			if err != nil {
+				return commented, err
+			}
+			commented = true
+		}
+	}
+
+	if s.JiraCommentsToGithub {
+		return commented, s.compareJiraComments(ctx, ghIssue, ghComments, jiraComments)
+	}
+	return commented, nil
+}
+
+// compareJiraComments copies Jira comments that were not written by the synchronization to the GitHub issue
+func (s *Sync) compareJiraComments(ctx context.Context, ghIssue *gh.Issue, ghComments []*gh.IssueComment, jiraComments []*jiralib.Comment) error {
+	for _, jc := range jiraComments {
+		if isSyncJiraComment(jc) {
+			// Do not copy back comments from GitHub or about the synchronization
+			continue
+		}
+		var commentFound bool
+		for _, ghc := range ghComments {
+			if strings.Contains(ghc.GetBody(), fmt.Sprintf("%s%s]", jiraCommentMarkerPrefix, jc.ID)) {
 				commentFound = true
-				if !strings.Contains(jc.Body, ghc.GetBody()) {
-					err := s.updateJiraComment(ghc, jiraIssue, jc)
+				if !strings.Contains(ghc.GetBody(), jc.Body) {
+					_, err := s.GithubClient.EditIssueComment(ctx, ghc.GetID(), getGHCommentBodyFromJiraComment(jc))
 					if err != nil {
 						return err
 					}
@@ -41,7 +99,7 @@ func (s *Sync) compareComments(ctx context.Context, ghIssue *gh.Issue, jiraIssue
 			}
 		}
 		if !commentFound {
-			err := s.createJiraComment(ghc, jiraIssue)
+			_, err := s.GithubClient.CreateIssueComment(ctx, ghIssue.GetNumber(), getGHCommentBodyFromJiraComment(jc))
 			if err != nil {
 				return err
 			}
@@ -50,16 +108,30 @@ func (s *Sync) compareComments(ctx context.Context, ghIssue *gh.Issue, jiraIssue
 	return nil
 }
 
-func getJiraCommentBodyFromGHComment(ghc *gh.IssueComment) string {
-	return fmt.Sprintf("GitHub Comment: ID: [%d], User: [%s]\n\n---------------------\n\n%s", ghc.GetID(), ghc.GetUser().GetLogin(), ghc.GetBody())
+// isSyncJiraComment returns true if the given Jira comment was written by the synchronization
+func isSyncJiraComment(jc *jiralib.Comment) bool {
+	return strings.Contains(jc.Body, ghCommentMarkerPrefix) || strings.Contains(jc.Body, syncCommentMarker)
+}
+
+// withSyncCommentMarker marks the body of a Jira comment written by the synchronization
+func withSyncCommentMarker(body string) string {
+	return body + "\n\n" + syncCommentMarker
+}
+
+func getJiraCommentBodyFromGHComment(ghc *gh.IssueComment, attachments map[string]string) string {
+	return fmt.Sprintf("%s%d], User: [%s]\n\n---------------------\n\n%s", ghCommentMarkerPrefix, ghc.GetID(), ghc.GetUser().GetLogin(), markup.ToJiraWikiWithAttachments(ghc.GetBody(), attachments))
+}
+
+func getGHCommentBodyFromJiraComment(jc *jiralib.Comment) string {
+	return fmt.Sprintf("%s%s], User: [%s]\n\n---------------------\n\n%s", jiraCommentMarkerPrefix, jc.ID, jc.Author.DisplayName, jc.Body)
 }
 
-func (s *Sync) createJiraComment(ghComment *gh.IssueComment, jiraIssue *jiralib.Issue) error {
-	_, err := s.JiraClient.AddComment(jiraIssue.Key, getJiraCommentBodyFromGHComment(ghComment))
+func (s *Sync) createJiraComment(ghComment *gh.IssueComment, jiraIssue *jiralib.Issue, attachments map[string]string) error {
+	_, err := s.JiraClient.AddComment(jiraIssue.Key, getJiraCommentBodyFromGHComment(ghComment, attachments))
 	return err
 }
 
-func (s *Sync) updateJiraComment(ghComment *gh.IssueComment, jiraIssue *jiralib.Issue, jiraComment *jiralib.Comment) error {
-	_, err := s.JiraClient.UpdateComment(jiraIssue.Key, jiraComment.ID, getJiraCommentBodyFromGHComment(ghComment))
+func (s *Sync) updateJiraComment(ghComment *gh.IssueComment, jiraIssue *jiralib.Issue, jiraComment *jiralib.Comment, attachments map[string]string) error {
+	_, err := s.JiraClient.UpdateComment(jiraIssue.Key, jiraComment.ID, getJiraCommentBodyFromGHComment(ghComment, attachments))
 	return err
 }
@@ -0,0 +1,99 @@
+package pkg
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+
+	jiralib "github.com/andygrunwald/go-jira"
+	gh "github.com/google/go-github/v24/github"
+
+	"github.com/ystia/zenhub-jira-sync/pkg/clients/github"
+	"github.com/ystia/zenhub-jira-sync/pkg/clients/jira"
+)
+
+// fakeCommentsGithub records comments written to GitHub
+type fakeCommentsGithub struct {
+	github.API
+	created []string
+	edited  []int64
+}
+
+func (f *fakeCommentsGithub) CreateIssueComment(ctx context.Context, issueNumber int, body string) (*gh.IssueComment, error) {
+	f.created = append(f.created, body)
+	return &gh.IssueComment{Body: &body}, nil
+}
+
+func (f *fakeCommentsGithub) EditIssueComment(ctx context.Context, commentID int64, body string) (*gh.IssueComment, error) {
+	f.edited = append(f.edited, commentID)
+	return &gh.IssueComment{ID: &commentID, Body: &body}, nil
+}
+
+// fakeCommentsJira records comments written to Jira
+type fakeCommentsJira struct {
+	jira.API
+	comments []*jiralib.Comment
+}
+
+func (f *fakeCommentsJira) AddComment(issueKeyOrID, body string) (*jiralib.Comment, error) {
+	comment := &jiralib.Comment{ID: fmt.Sprint(len(f.comments) + 1), Body: body}
+	f.comments = append(f.comments, comment)
+	return comment, nil
+}
+
+func TestCompareJiraComments(t *testing.T) {
+	jiraComment := func(id, body string) *jiralib.Comment {
+		return &jiralib.Comment{ID: id, Body: body, Author: jiralib.User{DisplayName: "John"}}
+	}
+	ghComment := func(id int64, jc *jiralib.Comment) *gh.IssueComment {
+		body := getGHCommentBodyFromJiraComment(jc)
+		return &gh.IssueComment{ID: &id, Body: &body}
+	}
+	mirrored := jiraComment("4", "Already mirrored")
+	edited := jiraComment("5", "Edited in Jira")
+	jiraComments := []*jiralib.Comment{
+		jiraComment("1", fmt.Sprintf("%s42], User: [octocat]\n\nFrom GitHub", ghCommentMarkerPrefix)),
+		jiraComment("2", withSyncCommentMarker("Reopened as the GitHub issue was reopened.")),
+		jiraComment("6", withSyncCommentMarker(orphanCommentPrefix+": the GitHub issue was deleted.")),
+		jiraComment("3", "Written in Jira"),
+		mirrored,
+		edited,
+	}
+	ghComments := []*gh.IssueComment{
+		ghComment(100, mirrored),
+		ghComment(101, jiraComment("5", "Before edition")),
+	}
+
+	fake := &fakeCommentsGithub{}
+	s := &Sync{GithubClient: fake}
+	number := 1
+	err := s.compareJiraComments(context.Background(), &gh.Issue{Number: &number}, ghComments, jiraComments)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(fake.created) != 1 || !strings.Contains(fake.created[0], jiraCommentMarkerPrefix+"3]") {
+		t.Errorf("expecting only the unmarked Jira comment to be copied to GitHub, got %q", fake.created)
+	}
+	if len(fake.edited) != 1 || fake.edited[0] != 101 {
+		t.Errorf("expecting only the mirror of the edited Jira comment to be updated, got %v", fake.edited)
+	}
+}
+
+func TestOrphanCommentWrittenBySync(t *testing.T) {
+	fake := &fakeCommentsJira{}
+	s := &Sync{JiraClient: fake, OrphanIssues: &OrphanIssues{Comment: true}}
+	jiraIssue := &jiralib.Issue{Key: "PRJ-1", Fields: &jiralib.IssueFields{}}
+	var outcomes itemOutcomes
+	err := s.handleOrphanIssue(jiraIssue, "the GitHub issue was deleted", &outcomes)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(fake.comments) != 1 || !isSyncJiraComment(fake.comments[0]) {
+		t.Fatalf("expecting a single comment written by the synchronization, got %v", fake.comments)
+	}
+	jiraIssue.Fields.Comments = &jiralib.Comments{Comments: fake.comments}
+	if !hasJiraComment(jiraIssue, orphanCommentPrefix) {
+		t.Errorf("marked orphan comment is not detected")
+	}
+}
@@ -0,0 +1,100 @@
+package pkg
+
+import (
+	"context"
+	"strings"
+
+	jiralib "github.com/andygrunwald/go-jira"
+	gh "github.com/google/go-github/v24/github"
+
+	"github.com/ystia/zenhub-jira-sync/pkg/clients/zenhub"
+)
+
+// isOwnIssue checks if the given ZenHub issue belongs to the repository of this synchronization
+func (s *Sync) isOwnIssue(ctx context.Context, issue *zenhub.Issue) (bool, error) {
+	if issue.RepoID == nil {
+		return true, nil
+	}
+	s.repositoriesLock.Lock()
+	defer s.repositoriesLock.Unlock()
+	if s.repositoryID == 0 {
+		repo, err := s.GithubClient.GetRepository(ctx)
+		if err != nil {
+			return false, err
+		}
+		s.repositoryID = repo.GetID()
+	}
+	return *issue.RepoID == s.repositoryID, nil
+}
+
+// issueRef returns the reference of the given ZenHub issue, the repository is kept for issues of other repositories
+func (s *Sync) issueRef(ctx context.Context, issue *zenhub.Issue) IssueRef {
+	ref := IssueRef{Number: *issue.IssueNumber}
+	if own, err := s.isOwnIssue(ctx, issue); err == nil && !own {
+		ref.RepoID = *issue.RepoID
+	}
+	return ref
+}
+
+// isSynchronizedRepository checks if the GitHub repository with the given ID is part of the synchronized repositories
+func (s *Sync) isSynchronizedRepository(ctx context.Context, repoID int64) (bool, error) {
+	s.repositoriesLock.Lock()
+	defer s.repositoriesLock.Unlock()
+	if synchronized, ok := s.synchronizedRepositories[repoID]; ok {
+		return synchronized, nil
+	}
+	repo, err := s.GithubClient.GetRepositoryByID(ctx, repoID)
+	if err != nil {
+		return false, err
+	}
+	var synchronized bool
+	for _, name := range s.SynchronizedRepositories {
+		if strings.EqualFold(name, repo.GetFullName()) {
+			synchronized = true
+			break
+		}
+	}
+	if s.synchronizedRepositories == nil {
+		s.synchronizedRepositories = make(map[int64]bool)
+	}
+	s.synchronizedRepositories[repoID] = synchronized
+	return synchronized, nil
+}
+
+// getJiraEpic returns the Jira epic of a ZenHub epic without synchronizing it.
+//
+// Epics of other repositories are only considered if their repository is synchronized too.
+// The returned epic is nil if the epic is closed, not considered or not yet synchronized.
+func (s *Sync) getJiraEpic(ctx context.Context, epic *zenhub.Epic) (*jiralib.Issue, error) {
+	own, err := s.isOwnIssue(ctx, epic.Issue)
+	if err != nil {
+		return nil, err
+	}
+	var ghEpic *gh.Issue
+	if own {
+		ghEpic, err = s.GithubClient.GetIssue(ctx, *epic.IssueNumber)
+	} else {
+		var synchronized bool
+		synchronized, err = s.isSynchronizedRepository(ctx, *epic.RepoID)
+		if err != nil || !synchronized {
+			return nil, err
+		}
+		ghEpic, err = s.GithubClient.GetIssueFromRepoID(ctx, *epic.RepoID, *epic.IssueNumber)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if ghEpic.GetState() == "closed" {
+		// Do not consider closed epics
+		return nil, nil
+	}
+	jiraEpic, err := s.JiraClient.GetIssueFromGithubID(ghEpic.GetID())
+	if err != nil {
+		return nil, err
+	}
+	if jiraEpic == nil {
+		// Epics of other repositories are created by the synchronization of their repository
+		s.logger().WithField("epic", ghEpic.GetHTMLURL()).Infof("Epic is not yet synchronized to Jira, ignoring it")
+	}
+	return jiraEpic, nil
+}
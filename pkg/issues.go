@@ -3,16 +3,19 @@ package pkg
 import (
 	"context"
 	"fmt"
-	"log"
 	"regexp"
 	"strconv"
 	"strings"
+	"sync"
+	"time"
 
 	jiralib "github.com/andygrunwald/go-jira"
 	gh "github.com/google/go-github/v24/github"
 
 	"github.com/ystia/zenhub-jira-sync/pkg/clients/jira"
 	"github.com/ystia/zenhub-jira-sync/pkg/clients/zenhub"
+	"github.com/ystia/zenhub-jira-sync/pkg/markup"
+	"github.com/ystia/zenhub-jira-sync/pkg/state"
 )
 
 var sprintIDRE = regexp.MustCompile(`id=(\d+)`)
@@ -24,38 +27,44 @@ func (s *Sync) issues(ctx context.Context, relTuples []releasesTuple) error {
 		sprintNamesToIDs[sprint.Name] = sprint.ID
 	}
 
-	issuesPerReleases := make(map[int][]string, 0)
+	issuesPerReleases := make(map[IssueRef][]string, 0)
 	for _, t := range relTuples {
 		issuesIDs, err := s.ZenhubClient.GetIssuesForReleaseReport(t.zhRelease.ID)
 		if err != nil {
 			return err
 		}
 		for _, i := range issuesIDs {
+			ref, err := s.releaseIssueRef(ctx, i)
+			if err != nil {
+				return err
+			}
 			// append to a nil slice works
-			issuesPerReleases[*i.IssueNumber] = append(issuesPerReleases[*i.IssueNumber], t.jiraVersion.ID)
+			issuesPerReleases[ref] = append(issuesPerReleases[ref], t.jiraVersion.ID)
 		}
 	}
 
+	errs := new(issuesErrorsCollector)
 	issuesToEpics := make(map[string]string)
+	var issuesToEpicsLock sync.Mutex
 
-	// First create Epics
+	// First create Epics, they should be synchronized before their issues
 	epics, err := s.ZenhubClient.GetEpics()
+	if err != nil {
+		return err
+	}
 
-	for _, epic := range epics {
-		ghIssue, err := s.GithubClient.GetIssue(ctx, *epic.IssueNumber)
+	s.forEach(len(epics), func(i int) {
+		epic := epics[i]
+		jiraEpic, err := s.checkEpic(ctx, epic, sprintNamesToIDs, issuesPerReleases)
 		if err != nil {
-			return err
-		}
-		epic.Issue.Issue = ghIssue
-		if epic.GetState() == "closed" {
-			// Do not consider closed epics
-			continue
+			errs.add(s.issueRef(ctx, epic.Issue), err)
+			return
 		}
-
-		jiraEpic, err := s.checkIssue(ctx, epic.Issue, "", sprintNamesToIDs, issuesPerReleases)
-		if err != nil {
-			return err
+		if jiraEpic == nil {
+			return
 		}
+		issuesToEpicsLock.Lock()
+		defer issuesToEpicsLock.Unlock()
 		for _, issue := range epic.Issues {
 			if issue.IsEpic {
 				// Jira doesn't support Epics within epics
@@ -63,63 +72,238 @@ func (s *Sync) issues(ctx context.Context, relTuples []releasesTuple) error {
 			}
 			issuesToEpics[fmt.Sprintf("%d/%d", *issue.RepoID, *issue.IssueNumber)] = jiraEpic.Key
 		}
-	}
+	})
 
 	board, err := s.ZenhubClient.GetBoard()
 	if err != nil {
 		return err
 	}
 
-	for _, pipeline := range board.Pipelines {
-		log.Printf("Synchronizing issues from pipeline %q", pipeline.Name)
+	// Synchronize issues of all pipelines at once and keep results in board order for ranking
+	type boardIssue struct {
+		pipeline  int
+		issue     *zenhub.Issue
+		jiraIssue *jiralib.Issue
+	}
+	boardIssues := make([]*boardIssue, 0)
+	for p, pipeline := range board.Pipelines {
+		sortIssuesByPosition(pipeline.Issues)
 		for _, issue := range pipeline.Issues {
 			if issue.IsEpic {
 				// epics already synchronized
 				continue
 			}
 			issue.Pipeline = &zenhub.IssueDataPipeline{Name: pipeline.Name}
-			epicKey := issuesToEpics[fmt.Sprintf("%d/%d", *issue.RepoID, *issue.IssueNumber)]
-			_, err = s.checkIssue(ctx, issue, epicKey, sprintNamesToIDs, issuesPerReleases)
+			boardIssues = append(boardIssues, &boardIssue{pipeline: p, issue: issue})
+		}
+	}
+	s.logger().Infof("Synchronizing %d issues from %d pipelines", len(boardIssues), len(board.Pipelines))
+	s.forEach(len(boardIssues), func(i int) {
+		bi := boardIssues[i]
+		epicKey := issuesToEpics[fmt.Sprintf("%d/%d", *bi.issue.RepoID, *bi.issue.IssueNumber)]
+		jiraIssue, err := s.checkIssue(ctx, bi.issue, epicKey, sprintNamesToIDs, issuesPerReleases)
+		if err != nil {
+			errs.add(s.issueRef(ctx, bi.issue), err)
+			return
+		}
+		bi.jiraIssue = jiraIssue
+	})
+
+	if s.RankIssues {
+		for p, pipeline := range board.Pipelines {
+			rankedIssues := make([]rankedIssue, 0, len(pipeline.Issues))
+			for _, bi := range boardIssues {
+				if bi.pipeline == p && bi.jiraIssue != nil {
+					rankedIssues = append(rankedIssues, s.getRankedIssue(bi.jiraIssue))
+				}
+			}
+			err = s.rankIssues(pipeline.Name, rankedIssues)
 			if err != nil {
 				return err
 			}
 		}
 	}
+
 	// Closed issues do not appear in ZH board
-	return s.checkClosedIssues(ctx)
+	closedIssuesIDs, err := s.checkClosedIssues(ctx, errs)
+	if err != nil {
+		return err
+	}
+
+	if s.OrphanIssues != nil {
+		liveIssues := make(map[int64]bool, len(boardIssues)+len(epics)+len(closedIssuesIDs))
+		for _, id := range closedIssuesIDs {
+			liveIssues[id] = true
+		}
+		for _, epic := range epics {
+			if epic.Issue != nil && epic.Issue.Issue != nil {
+				liveIssues[epic.GetID()] = true
+			}
+		}
+		for _, bi := range boardIssues {
+			if bi.issue.Issue != nil {
+				liveIssues[bi.issue.GetID()] = true
+			}
+		}
+		err = s.checkOrphanIssues(ctx, liveIssues, errs)
+		if err != nil {
+			return err
+		}
+	}
+	return errs.err()
+}
+
+// checkEpic synchronizes an epic of this repository and returns its Jira epic.
+//
+// Epics of other repositories are not synchronized, as this is done by the synchronization of their repository,
+// only their Jira epic is looked up. The returned epic is nil if it should not be considered.
+func (s *Sync) checkEpic(ctx context.Context, epic *zenhub.Epic, sprintNamesToIDs map[string]int, issuesPerReleases map[IssueRef][]string) (*jiralib.Issue, error) {
+	own, err := s.isOwnIssue(ctx, epic.Issue)
+	if err != nil {
+		return nil, err
+	}
+	if !own {
+		return s.getJiraEpic(ctx, epic)
+	}
+	ghIssue, err := s.GithubClient.GetIssue(ctx, *epic.IssueNumber)
+	if err != nil {
+		return nil, err
+	}
+	epic.Issue.Issue = ghIssue
+	if epic.GetState() == "closed" {
+		// Do not consider closed epics
+		return nil, nil
+	}
+	return s.checkIssue(ctx, epic.Issue, "", sprintNamesToIDs, issuesPerReleases)
 }
 
-func (s *Sync) checkClosedIssues(ctx context.Context) error {
-	log.Printf("Checking issues closed on GitHub")
+// checkClosedIssues closes the Jira issues of closed GitHub issues and returns the IDs of these GitHub issues
+func (s *Sync) checkClosedIssues(ctx context.Context, errs *issuesErrorsCollector) ([]int64, error) {
+	s.logger().Infof("Checking issues closed on GitHub")
 	closedIssues, err := s.GithubClient.ListIssues(ctx, &gh.IssueListByRepoOptions{
 		State: "closed",
 	})
 	if err != nil {
-		return err
+		return nil, err
 	}
 
+	ids := make([]int64, 0, len(closedIssues))
 	for _, issue := range closedIssues {
-		jiraIssue, err := s.JiraClient.GetIssueFromGithubID(issue.GetID())
-		if err != nil {
-			return err
+		ids = append(ids, issue.GetID())
+	}
+	s.forEach(len(closedIssues), func(i int) {
+		errs.add(IssueRef{Number: closedIssues[i].GetNumber()}, s.checkClosedIssue(closedIssues[i]))
+	})
+	return ids, nil
+}
+
+// checkClosedIssue closes the Jira issue associated to the given closed GitHub issue
+func (s *Sync) checkClosedIssue(issue *gh.Issue) error {
+	if s.State != nil {
+		st, ok := s.State.GetIssue(issue.GetID())
+		if ok && st.Closed && st.UpdatedAt.Equal(issue.GetUpdatedAt()) {
+			// Already closed during a previous synchronization
+			s.Report.add(ReportEntityIssues, itemOutcomes{}, nil)
+			return nil
 		}
-		if jiraIssue == nil {
-			continue
+	}
+	jiraIssue, err := s.JiraClient.GetIssueFromGithubID(issue.GetID())
+	if err != nil {
+		s.Report.add(ReportEntityIssues, itemOutcomes{}, err)
+		return err
+	}
+	if jiraIssue == nil {
+		return nil
+	}
+	t := s.closedIssuesTransition()
+	status, err := s.applyStatusTransition(jiraIssue.Key, jiraIssue.Fields.Status, t)
+	s.Report.add(ReportEntityIssues, itemOutcomes{transitioned: status != nil && !t.isTarget(jiraIssue.Fields.Status)}, err)
+	if err != nil {
+		return err
+	}
+	if status != nil && s.State != nil {
+		// Keep written labels to be able to remove them if the issue is reopened
+		previous, _ := s.State.GetIssue(issue.GetID())
+		s.State.SetIssue(issue.GetID(), state.IssueState{
+			JiraKey:        jiraIssue.Key,
+			UpdatedAt:      issue.GetUpdatedAt(),
+			Closed:         true,
+			JiraLabels:     previous.JiraLabels,
+			RuleComponents: previous.RuleComponents,
+		})
+	}
+	return nil
+}
+
+// checkReopenedIssue reopens the Jira issue of an open GitHub issue if it was closed before the GitHub issue
+// was reopened. Jira issues closed in Jira while their GitHub issue stayed open are left as is.
+//
+// It returns true if the Jira issue was reopened.
+func (s *Sync) checkReopenedIssue(ctx context.Context, issue *zenhub.Issue, jiraIssue *jiralib.Issue) (bool, error) {
+	if issue.GetState() != "open" || jiraIssue.Fields == nil || !s.closedIssuesTransition().isTarget(jiraIssue.Fields.Status) {
+		return false, nil
+	}
+	events, err := s.GithubClient.ListIssueEvents(ctx, issue.GetNumber())
+	if err != nil {
+		return false, err
+	}
+	var reopened *gh.IssueEvent
+	for _, e := range events {
+		if e.GetEvent() == "reopened" {
+			reopened = e
 		}
-		if jiraIssue.Fields.Status != nil && !(jiraIssue.Fields.Status.Name == "Closed" || jiraIssue.Fields.Status.Name == "Done") {
-			err = s.JiraClient.TransitionIssue(jiraIssue.Key, "Close Issue")
-			if err != nil {
-				err = s.JiraClient.TransitionIssue(jiraIssue.Key, "Done")
-				if err != nil {
-					return err
-				}
-			}
+	}
+	if reopened == nil {
+		return false, nil
+	}
+	closedAt := time.Time(jiraIssue.Fields.Resolutiondate)
+	if closedAt.IsZero() {
+		// Workflows may not set a resolution, use the date the issue moved to its closed status
+		closedAt, err = s.JiraClient.GetLastStatusChange(jiraIssue.Key)
+		if err != nil {
+			return false, err
 		}
 	}
-	return nil
+	if !reopenedAfterClosing(reopened.GetCreatedAt(), closedAt) {
+		// Closed in Jira after the GitHub issue was reopened
+		return false, nil
+	}
+	status, err := s.applyStatusTransition(jiraIssue.Key, jiraIssue.Fields.Status, s.reopenedIssuesTransition())
+	if err != nil || status == nil {
+		return false, err
+	}
+	// Keep the status up to date for the pipeline status check
+	jiraIssue.Fields.Status = status
+	actor := reopened.GetActor()
+	_, err = s.JiraClient.AddComment(jiraIssue.Key, withSyncCommentMarker(fmt.Sprintf("Reopened as the GitHub issue was reopened by [%s|%s] on %s.",
+		actor.GetLogin(), actor.GetHTMLURL(), reopened.GetCreatedAt().Format("2006-01-02 15:04 MST"))))
+	return true, err
+}
+
+// reopenedAfterClosing checks if a GitHub issue was reopened after its Jira issue was closed, a zero closing date
+// means that it is unknown and the Jira issue is then not considered as closed before
+func reopenedAfterClosing(reopenedAt, closedAt time.Time) bool {
+	return !closedAt.IsZero() && reopenedAt.After(closedAt)
+}
+
+// checkIssue synchronizes a GitHub issue or epic with its Jira issue and counts it in the synchronization report
+func (s *Sync) checkIssue(ctx context.Context, issue *zenhub.Issue, epicKey string, sprintNamesToIDs map[string]int, issuesPerReleases map[IssueRef][]string) (*jiralib.Issue, error) {
+	var outcomes itemOutcomes
+	jiraIssue, err := s.syncIssue(ctx, issue, epicKey, sprintNamesToIDs, issuesPerReleases, &outcomes)
+	entity := ReportEntityIssues
+	if issue.IsEpic {
+		entity = ReportEntityEpics
+	}
+	s.Report.add(entity, outcomes, err)
+	if err != nil {
+		s.logger().WithField("issue", issue.GetNumber()).Errorf("Failed to synchronize issue: %v", err)
+	}
+	return jiraIssue, err
 }
 
-func (s *Sync) checkIssue(ctx context.Context, issue *zenhub.Issue, epicKey string, sprintNamesToIDs map[string]int, issuesPerReleases map[int][]string) (*jiralib.Issue, error) {
+// syncIssue synchronizes a GitHub issue or epic with its Jira issue, changes applied to the Jira issue are recorded
+// into outcomes
+func (s *Sync) syncIssue(ctx context.Context, issue *zenhub.Issue, epicKey string, sprintNamesToIDs map[string]int, issuesPerReleases map[IssueRef][]string, outcomes *itemOutcomes) (*jiralib.Issue, error) {
 	if issue.Issue == nil {
 		ghIssue, err := s.GithubClient.GetIssue(ctx, *issue.IssueNumber)
 		if err != nil {
@@ -128,14 +312,49 @@ func (s *Sync) checkIssue(ctx context.Context, issue *zenhub.Issue, epicKey stri
 		issue.Issue = ghIssue
 	}
 
+	var fieldsHash string
+	var ghComments []*gh.IssueComment
+	if s.State != nil {
+		fieldsHash = s.issueFieldsHash(issue, epicKey, issuesPerReleases)
+		unchangedIssue, comments, err := s.getUnchangedIssue(ctx, issue, fieldsHash)
+		if err != nil {
+			return nil, err
+		}
+		if unchangedIssue != nil {
+			return unchangedIssue, nil
+		}
+		ghComments = comments
+	}
+
+	if ghComments == nil {
+		var err error
+		ghComments, err = s.getGithubComments(ctx, issue.Issue)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	users, err := s.getIssueUsers(ctx, issue)
+	if err != nil {
+		return nil, err
+	}
+
+	var attachments map[string]string
 	jiraIssue, err := s.JiraClient.GetIssueFromGithubID(issue.GetID())
 	if err != nil {
 		return nil, err
 	}
 	if jiraIssue != nil {
-		jiraIssueUpdate, changed, moveToBacklog, updateEstimate := s.diffIssues(issue, jiraIssue, epicKey, sprintNamesToIDs)
+		attachments, err = s.mirrorAttachments(ctx, jiraIssue, issue.GetBody(), ghComments)
+		if err != nil {
+			return nil, err
+		}
+		jiraIssueUpdate, changed, moveToBacklog, updateEstimate := s.diffIssues(issue, jiraIssue, epicKey, sprintNamesToIDs, users, attachments)
+		outcomes.updated = changed || moveToBacklog || updateEstimate
 		if changed {
-			jiraIssue, err = s.JiraClient.UpdateIssue(jiraIssueUpdate)
+			// Do not override jiraIssue with the update result as it only contains updated fields
+			// while status, comments and fix versions are used bellow
+			_, err = s.JiraClient.UpdateIssue(jiraIssueUpdate)
 			if err != nil {
 				return nil, err
 			}
@@ -158,27 +377,58 @@ func (s *Sync) checkIssue(ctx context.Context, issue *zenhub.Issue, epicKey stri
 			}
 		}
 
-		err = s.checkAndUpdateFixVersions(issue, jiraIssue, issuesPerReleases)
+		updatedFixVersions, err := s.checkAndUpdateFixVersions(issue, jiraIssue, issuesPerReleases)
 		if err != nil {
 			return nil, err
 		}
+		outcomes.updated = outcomes.updated || updatedFixVersions
 		err = s.checkContainsRemoteURL(jiraIssue, "Original GitHub Issue", issue.GetHTMLURL())
 		if err != nil {
 			return nil, err
 		}
+		outcomes.transitioned, err = s.checkReopenedIssue(ctx, issue, jiraIssue)
+		if err != nil {
+			return nil, err
+		}
 	} else {
-		jiraIssue, err = s.createJiraIssueFromZenHubIssue(issue, epicKey, sprintNamesToIDs)
+		jiraIssue, err = s.createJiraIssueFromZenHubIssue(issue, epicKey, sprintNamesToIDs, users)
 		if err != nil {
 			return nil, err
 		}
+		outcomes.created = true
+		attachments, err = s.mirrorAttachments(ctx, jiraIssue, issue.GetBody(), ghComments)
+		if err != nil {
+			return nil, err
+		}
+		// References to attachments could only be rewritten once the issue exists
+		description := markup.ToJiraWikiWithAttachments(issue.GetBody(), attachments)
+		if description != markup.ToJiraWiki(issue.GetBody()) {
+			_, err = s.JiraClient.UpdateIssue(&jiralib.Issue{Key: jiraIssue.Key, Fields: &jiralib.IssueFields{Description: description}})
+			if err != nil {
+				return nil, err
+			}
+		}
 	}
-	err = s.compareComments(ctx, issue.Issue, jiraIssue)
-	return jiraIssue, err
+	transitioned, err := s.checkPipelineStatus(issue, jiraIssue)
+	if err != nil {
+		return nil, err
+	}
+	outcomes.transitioned = outcomes.transitioned || transitioned
+	outcomes.commented, err = s.compareComments(ctx, issue.Issue, ghComments, jiraIssue, attachments)
+	if err != nil {
+		return nil, err
+	}
+	s.recordIssueState(issue, jiraIssue, fieldsHash, ghComments)
+	return jiraIssue, nil
 }
 
-func (s *Sync) checkAndUpdateFixVersions(zhIssue *zenhub.Issue, jiraIssue *jiralib.Issue, issuesPerReleases map[int][]string) error {
-	releases := issuesPerReleases[*zhIssue.IssueNumber]
+// checkAndUpdateFixVersions updates fix versions of the Jira issue and returns true if they changed
+func (s *Sync) checkAndUpdateFixVersions(zhIssue *zenhub.Issue, jiraIssue *jiralib.Issue, issuesPerReleases map[IssueRef][]string) (bool, error) {
 	if jiraIssue.Fields.Type.Name != "Bug" || zhIssue.GetState() != "Closed" {
+		releases, err := s.issueFixVersions(zhIssue, issuesPerReleases)
+		if err != nil {
+			return false, err
+		}
 		var updateFixVersions bool
 		if len(releases) != len(jiraIssue.Fields.FixVersions) {
 			updateFixVersions = true
@@ -199,16 +449,42 @@ func (s *Sync) checkAndUpdateFixVersions(zhIssue *zenhub.Issue, jiraIssue *jiral
 		}
 		if updateFixVersions {
 			err := s.JiraClient.UpdateIssueFixVersion(jiraIssue.Key, releases)
-			if err != nil {
-				return err
-			}
+			return err == nil, err
 		}
 
 	}
-	return nil
+	return false, nil
 }
 
-func (s *Sync) diffIssues(zhIssue *zenhub.Issue, jiraIssue *jiralib.Issue, epicKey string, sprintNamesToIDs map[string]int) (*jiralib.Issue, bool, bool, bool) {
+// issueFixVersions returns the IDs of the fix versions of an issue, from ZenHub releases and label rules
+// releaseIssueRef returns the reference of an issue of a release report, release reports may contain issues of
+// several repositories
+func (s *Sync) releaseIssueRef(ctx context.Context, issueID zenhub.IssueID) (IssueRef, error) {
+	ref := IssueRef{Number: *issueID.IssueNumber}
+	own, err := s.isOwnIssue(ctx, &zenhub.Issue{IssueID: issueID})
+	if err != nil {
+		return ref, err
+	}
+	if !own {
+		ref.RepoID = *issueID.RepoID
+	}
+	return ref, nil
+}
+
+func (s *Sync) issueFixVersions(zhIssue *zenhub.Issue, issuesPerReleases map[IssueRef][]string) ([]string, error) {
+	releases := issuesPerReleases[IssueRef{Number: zhIssue.GetNumber()}]
+	rulesVersions, err := s.getVersionsIDs(applyLabelRules(s.LabelRules, getZHIssueLabels(zhIssue)).fixVersions)
+	if err != nil || len(rulesVersions) == 0 {
+		return releases, err
+	}
+	versions := append([]string(nil), releases...)
+	for _, id := range rulesVersions {
+		versions = appendMissing(versions, id)
+	}
+	return versions, nil
+}
+
+func (s *Sync) diffIssues(zhIssue *zenhub.Issue, jiraIssue *jiralib.Issue, epicKey string, sprintNamesToIDs map[string]int, users *issueUsers, attachments map[string]string) (*jiralib.Issue, bool, bool, bool) {
 	var updatedIssue bool
 	var moveToBacklog bool
 	resultIssue := &jiralib.Issue{
@@ -219,27 +495,69 @@ func (s *Sync) diffIssues(zhIssue *zenhub.Issue, jiraIssue *jiralib.Issue, epicK
 		},
 	}
 
-	if s.checkDefaultComponentsOnIssue(jiraIssue) {
+	rules := applyLabelRules(s.LabelRules, getZHIssueLabels(zhIssue))
+	components := s.issueComponents(rules)
+	if s.checkComponentsOnIssue(jiraIssue, components, s.obsoleteRuleComponents(zhIssue, components)) {
+		updatedIssue = true
+		if len(jiraIssue.Fields.Components) > 0 {
+			resultIssue.Fields.Components = jiraIssue.Fields.Components
+		} else {
+			// Use an unknown field as the Components field is omitted when empty
+			resultIssue.Fields.Unknowns["components"] = []interface{}{}
+		}
+	}
+	if s.checkLabelRules(rules, jiraIssue, resultIssue) {
 		updatedIssue = true
-		resultIssue.Fields.Components = jiraIssue.Fields.Components
 	}
 
 	if zhIssue.GetTitle() != jiraIssue.Fields.Summary {
 		updatedIssue = true
 		resultIssue.Fields.Summary = zhIssue.GetTitle()
 	}
-	if zhIssue.GetBody() != jiraIssue.Fields.Description {
+	// Compare the converted form to not rewrite descriptions on every run
+	description := markup.ToJiraWikiWithAttachments(zhIssue.GetBody(), attachments)
+	if !markup.Equal(description, jiraIssue.Fields.Description) {
 		updatedIssue = true
-		resultIssue.Fields.Description = zhIssue.GetBody()
+		resultIssue.Fields.Description = description
 	}
-	zhLabels := strings.Join(getZHIssueLabels(zhIssue), " ")
-	var jLabels string
-	if v, ok := jiraIssue.Fields.Unknowns[s.JiraClient.GetCustomFieldID(jira.CFNameGitHubLabels)]; ok {
-		jLabels, ok = v.(string)
+	if cfID := s.JiraClient.GetCustomFieldID(jira.CFNameGitHubReporter); cfID != "" {
+		var ghReporter string
+		if v, ok := jiraIssue.Fields.Unknowns[cfID]; ok {
+			ghReporter, _ = v.(string)
+		}
+		if ghReporter != zhIssue.GetUser().GetLogin() {
+			updatedIssue = true
+			resultIssue.Fields.Unknowns[cfID] = zhIssue.GetUser().GetLogin()
+		}
+	}
+	if users != nil {
+		if users.reporter != "" && users.reporter != s.JiraClient.GetUserID(jiraIssue.Fields.Reporter) {
+			updatedIssue = true
+			resultIssue.Fields.Reporter = s.JiraClient.NewUserFromID(users.reporter)
+		}
+		jiraAssignee := s.JiraClient.GetUserID(jiraIssue.Fields.Assignee)
+		if users.assignee != "" && users.assignee != jiraAssignee {
+			updatedIssue = true
+			resultIssue.Fields.Assignee = s.JiraClient.NewUserFromID(users.assignee)
+		} else if users.unassigned && s.UserMapping.Unassign && jiraAssignee != "" {
+			// Unmapped assignees are ignored, a nil value unassigns the issue
+			updatedIssue = true
+			resultIssue.Fields.Unknowns["assignee"] = nil
+		}
 	}
-	if jLabels != zhLabels {
+	if s.checkNativeLabels(zhIssue, jiraIssue, resultIssue) {
 		updatedIssue = true
-		resultIssue.Fields.Unknowns[s.JiraClient.GetCustomFieldID(jira.CFNameGitHubLabels)] = zhLabels
+	}
+	if cfID := s.JiraClient.GetCustomFieldID(jira.CFNameGitHubLabels); cfID != "" {
+		zhLabels := strings.Join(getZHIssueLabels(zhIssue), " ")
+		var jLabels string
+		if v, ok := jiraIssue.Fields.Unknowns[cfID]; ok {
+			jLabels, _ = v.(string)
+		}
+		if jLabels != zhLabels {
+			updatedIssue = true
+			resultIssue.Fields.Unknowns[cfID] = zhLabels
+		}
 	}
 
 	sprintRef := jiraIssue.Fields.Unknowns[s.JiraClient.GetCustomFieldID(jira.CFNameSprint)]
@@ -257,13 +575,21 @@ func (s *Sync) diffIssues(zhIssue *zenhub.Issue, jiraIssue *jiralib.Issue, epicK
 		resultIssue.Fields.Unknowns[s.JiraClient.GetCustomFieldID(jira.CFNameSprint)] = sprintNamesToIDs[zhIssue.Milestone.GetTitle()]
 	}
 
-	if jiraIssue.Fields.Unknowns[s.JiraClient.GetCustomFieldID(jira.CFNameGitHubStatus)] != zhIssue.GetState() {
+	if cfID := s.JiraClient.GetCustomFieldID(jira.CFNameGitHubStatus); cfID != "" && jiraIssue.Fields.Unknowns[cfID] != zhIssue.GetState() {
 		updatedIssue = true
-		resultIssue.Fields.Unknowns[s.JiraClient.GetCustomFieldID(jira.CFNameGitHubStatus)] = zhIssue.GetState()
+		resultIssue.Fields.Unknowns[cfID] = zhIssue.GetState()
+	}
+
+	// An empty epic key may be an epic not synchronized yet or closed, do not unlink the issue in this case
+	if cfID := s.JiraClient.GetCustomFieldID(jira.CFNameEpicLink); cfID != "" && epicKey != "" {
+		if currentEpicKey, _ := jiraIssue.Fields.Unknowns[cfID].(string); currentEpicKey != epicKey {
+			updatedIssue = true
+			resultIssue.Fields.Unknowns[cfID] = epicKey
+		}
 	}
 
 	var updateEstimate bool
-	if jira.IsIssueTypeEstimable(jiraIssue.Fields.Type.Name) {
+	if s.JiraClient.IsIssueTypeEstimable(jiraIssue.Fields.Type.Name) {
 		var estimate int
 		jiraSP, err := s.JiraClient.GetIssueEstimate(jiraIssue.ID)
 		if err == nil {
@@ -274,15 +600,28 @@ func (s *Sync) diffIssues(zhIssue *zenhub.Issue, jiraIssue *jiralib.Issue, epicK
 				updateEstimate = true
 			}
 		} else {
-			log.Printf("failed to get issue estimate %v, do not update it", err)
+			s.logger().WithField("issue", zhIssue.GetNumber()).WithField("jira_key", jiraIssue.Key).Warnf("Failed to get issue estimate, do not update it: %v", err)
 		}
 	}
 	return resultIssue, updatedIssue, moveToBacklog, updateEstimate
 }
 
-func (s *Sync) checkDefaultComponentsOnIssue(jiraIssue *jiralib.Issue) bool {
+// checkComponentsOnIssue adds the given components to the Jira issue if missing and removes the obsolete ones,
+// it returns true if components changed
+func (s *Sync) checkComponentsOnIssue(jiraIssue *jiralib.Issue, components, obsolete []string) bool {
 	var updated bool
-	for _, defComp := range s.DefaultJiraComponents {
+	if len(obsolete) > 0 {
+		kept := make([]*jiralib.Component, 0, len(jiraIssue.Fields.Components))
+		for _, comp := range jiraIssue.Fields.Components {
+			if containsString(obsolete, comp.Name) {
+				updated = true
+				continue
+			}
+			kept = append(kept, comp)
+		}
+		jiraIssue.Fields.Components = kept
+	}
+	for _, defComp := range components {
 		var found bool
 		for _, comp := range jiraIssue.Fields.Components {
 			if comp.Name == defComp {
@@ -298,7 +637,7 @@ func (s *Sync) checkDefaultComponentsOnIssue(jiraIssue *jiralib.Issue) bool {
 	return updated
 }
 
-func (s *Sync) createJiraIssueFromZenHubIssue(issue *zenhub.Issue, epicKey string, sprintNamesToIDs map[string]int) (*jiralib.Issue, error) {
+func (s *Sync) createJiraIssueFromZenHubIssue(issue *zenhub.Issue, epicKey string, sprintNamesToIDs map[string]int, users *issueUsers) (*jiralib.Issue, error) {
 
 	issueType := s.DefaultIssueType
 	for _, pair := range s.LabelsToIssueType {
@@ -313,11 +652,52 @@ func (s *Sync) createJiraIssueFromZenHubIssue(issue *zenhub.Issue, epicKey strin
 		*sprint = sprintNamesToIDs[issue.GetMilestone().GetTitle()]
 	}
 
-	jiraIssue, err := s.JiraClient.CreateIssue(issueType, issue.GetTitle(), issue.GetBody(), epicKey, s.DefaultJiraComponents, sprint, issue.GetID(), issue.GetNumber(), getZHIssueLabels(issue), issue.GetState())
+	var reporter, assignee *jiralib.User
+	if users != nil && users.reporter != "" {
+		reporter = s.JiraClient.NewUserFromID(users.reporter)
+	}
+	if users != nil && users.assignee != "" {
+		assignee = s.JiraClient.NewUserFromID(users.assignee)
+	}
+
+	var labels []string
+	if s.NativeLabels != nil {
+		labels = s.NativeLabels.jiraLabels(getZHIssueLabels(issue))
+	}
+	rules := applyLabelRules(s.LabelRules, getZHIssueLabels(issue))
+	fields := s.labelRulesFields(rules)
+	fixVersions, err := s.getVersionsIDs(rules.fixVersions)
+	if err != nil {
+		return nil, err
+	}
+	if len(fixVersions) > 0 {
+		versions := make([]interface{}, len(fixVersions))
+		for i, id := range fixVersions {
+			versions[i] = map[string]interface{}{"id": id}
+		}
+		fields["fixVersions"] = versions
+	}
+	jiraIssue, err := s.JiraClient.CreateIssue(jira.CreateIssueOptions{
+		IssueType:      issueType,
+		Summary:        issue.GetTitle(),
+		Description:    markup.ToJiraWiki(issue.GetBody()),
+		EpicKey:        epicKey,
+		Components:     s.issueComponents(rules),
+		Labels:         labels,
+		Sprint:         sprint,
+		GithubID:       issue.GetID(),
+		GithubNumber:   issue.GetNumber(),
+		GithubLabels:   getZHIssueLabels(issue),
+		GithubStatus:   issue.GetState(),
+		GithubReporter: issue.GetUser().GetLogin(),
+		Reporter:       reporter,
+		Assignee:       assignee,
+		Fields:         fields,
+	})
 	if err != nil {
 		return jiraIssue, err
 	}
-	if issue.Estimate != nil && jira.IsIssueTypeEstimable(issueType) {
+	if issue.Estimate != nil && s.JiraClient.IsIssueTypeEstimable(issueType) {
 		err = s.JiraClient.UpdateIssueEstimate(jiraIssue.ID, float32(issue.Estimate.Value))
 		if err != nil {
 			return jiraIssue, err
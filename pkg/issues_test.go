@@ -0,0 +1,151 @@
+package pkg
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	jiralib "github.com/andygrunwald/go-jira"
+	gh "github.com/google/go-github/v24/github"
+
+	"github.com/ystia/zenhub-jira-sync/pkg/clients/github"
+	"github.com/ystia/zenhub-jira-sync/pkg/clients/jira"
+	"github.com/ystia/zenhub-jira-sync/pkg/clients/zenhub"
+)
+
+// fakeFieldsJira is a Jira API only knowing custom fields IDs
+type fakeFieldsJira struct {
+	jira.API
+	customFields map[string]string
+}
+
+func (f *fakeFieldsJira) GetCustomFieldID(name string) string {
+	return f.customFields[name]
+}
+
+func (f *fakeFieldsJira) GetIssueFromGithubID(githubID int64) (*jiralib.Issue, error) {
+	return nil, nil
+}
+
+func (f *fakeFieldsJira) IsIssueTypeEstimable(issueTypeName string) bool {
+	return false
+}
+
+func TestReopenedAfterClosing(t *testing.T) {
+	closedAt := time.Date(2020, 5, 4, 10, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name       string
+		reopenedAt time.Time
+		closedAt   time.Time
+		expected   bool
+	}{
+		{"ReopenedAfter", closedAt.Add(time.Hour), closedAt, true},
+		{"ClosedInJiraAfter", closedAt.Add(-time.Hour), closedAt, false},
+		{"UnknownClosingDate", closedAt, time.Time{}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := reopenedAfterClosing(tt.reopenedAt, tt.closedAt); got != tt.expected {
+				t.Errorf("reopenedAfterClosing(%v, %v) = %v, expecting %v", tt.reopenedAt, tt.closedAt, got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestDiffIssuesEpicLink(t *testing.T) {
+	tests := []struct {
+		name            string
+		currentEpicKey  interface{}
+		epicKey         string
+		expectedUpdated bool
+	}{
+		// The child was created before its epic was synchronized
+		{"EpicSynchronizedAfterChild", nil, "PRJ-1", true},
+		{"EpicChanged", "PRJ-2", "PRJ-1", true},
+		{"AlreadyLinked", "PRJ-1", "PRJ-1", false},
+		{"EpicNotSynchronized", "PRJ-1", "", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := &Sync{JiraClient: &fakeFieldsJira{customFields: map[string]string{jira.CFNameEpicLink: "customfield_10014"}}}
+			title := "Child issue"
+			zhIssue := &zenhub.Issue{Issue: &gh.Issue{Title: &title}}
+			jiraIssue := &jiralib.Issue{Key: "PRJ-3", Fields: &jiralib.IssueFields{
+				Summary:  title,
+				Type:     jiralib.IssueType{Name: "Story"},
+				Unknowns: map[string]interface{}{},
+			}}
+			if tt.currentEpicKey != nil {
+				jiraIssue.Fields.Unknowns["customfield_10014"] = tt.currentEpicKey
+			}
+			result, updated, _, _ := s.diffIssues(zhIssue, jiraIssue, tt.epicKey, nil, nil, nil)
+			if updated != tt.expectedUpdated {
+				t.Fatalf("diffIssues() updated = %v, expecting %v", updated, tt.expectedUpdated)
+			}
+			if updated && result.Fields.Unknowns["customfield_10014"] != tt.epicKey {
+				t.Errorf("epic link = %v, expecting %q", result.Fields.Unknowns["customfield_10014"], tt.epicKey)
+			}
+		})
+	}
+}
+
+// fakeRepositoryGithub is a GitHub API only knowing its repository and its closed issues
+type fakeRepositoryGithub struct {
+	github.API
+	repoID       int64
+	closedIssues []*gh.Issue
+}
+
+func (f *fakeRepositoryGithub) ListIssues(ctx context.Context, opts *gh.IssueListByRepoOptions) ([]*gh.Issue, error) {
+	return f.closedIssues, nil
+}
+
+func (f *fakeRepositoryGithub) GetRepository(ctx context.Context) (*gh.Repository, error) {
+	return &gh.Repository{ID: &f.repoID}, nil
+}
+
+func TestReleaseIssueRef(t *testing.T) {
+	number := 42
+	ownRepo, otherRepo := int64(1), int64(2)
+	tests := []struct {
+		name     string
+		repoID   *int64
+		expected IssueRef
+	}{
+		{"NoRepository", nil, IssueRef{Number: 42}},
+		{"OwnRepository", &ownRepo, IssueRef{Number: 42}},
+		{"OtherRepository", &otherRepo, IssueRef{RepoID: 2, Number: 42}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := &Sync{GithubClient: &fakeRepositoryGithub{repoID: ownRepo}}
+			ref, err := s.releaseIssueRef(context.Background(), zenhub.IssueID{IssueNumber: &number, RepoID: tt.repoID})
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if ref != tt.expected {
+				t.Errorf("releaseIssueRef() = %v, expecting %v", ref, tt.expected)
+			}
+		})
+	}
+}
+
+func TestCheckClosedIssuesReturnsIDs(t *testing.T) {
+	closedIssue := func(id int64, number int) *gh.Issue {
+		return &gh.Issue{ID: &id, Number: &number}
+	}
+	s := &Sync{
+		GithubClient: &fakeRepositoryGithub{closedIssues: []*gh.Issue{closedIssue(100, 1), closedIssue(101, 2)}},
+		JiraClient:   &fakeFieldsJira{},
+		Concurrency:  2,
+	}
+	errs := new(issuesErrorsCollector)
+	ids, err := s.checkClosedIssues(context.Background(), errs)
+	if err != nil || errs.err() != nil {
+		t.Fatalf("unexpected error: %v %v", err, errs.err())
+	}
+	// Closed issues are excluded from orphan candidates
+	if len(ids) != 2 || ids[0] != 100 || ids[1] != 101 {
+		t.Errorf("checkClosedIssues() = %v, expecting [100 101]", ids)
+	}
+}
@@ -0,0 +1,239 @@
+package pkg
+
+import (
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	jiralib "github.com/andygrunwald/go-jira"
+	"github.com/pkg/errors"
+
+	"github.com/ystia/zenhub-jira-sync/pkg/clients/zenhub"
+)
+
+// LabelRule sets Jira fields of issues having a GitHub label matching its pattern.
+//
+// Values may reference submatches of the pattern such as $1 or ${name}. Rules are applied in order, when several
+// rules or labels set the priority or the same field the first matching rule wins, labels being considered in
+// alphabetical order. Components and fix versions of all matching rules are added to issues. Fix versions of issues
+// are replaced by the ones of ZenHub releases and matching rules, while components are only removed if they were
+// added by a rule that does not match anymore, which requires a synchronization State.
+type LabelRule struct {
+	Pattern     *regexp.Regexp
+	Priority    string
+	Components  []string
+	FixVersions []string
+	Fields      []LabelRuleField
+}
+
+// LabelRuleField is a value of a Jira field set by a label rule
+type LabelRuleField struct {
+	// ID is the field ID such as customfield_10010
+	ID string
+	// Type is the type of the field as defined by its Jira schema: number, option and array fields values are
+	// respectively converted to numbers, options and lists of options, other fields are set as text
+	Type  string
+	Value string
+}
+
+// labelRulesResult are fields values of an issue resulting of label rules
+type labelRulesResult struct {
+	priority    string
+	components  []string
+	fixVersions []string
+	fields      []LabelRuleField
+}
+
+// applyLabelRules returns the fields values set by the given rules on an issue having the given labels
+func applyLabelRules(rules []LabelRule, labels []string) labelRulesResult {
+	var result labelRulesResult
+	sortedLabels := append([]string(nil), labels...)
+	sort.Strings(sortedLabels)
+	setFields := make(map[string]bool)
+	for _, rule := range rules {
+		for _, label := range sortedLabels {
+			match := rule.Pattern.FindStringSubmatchIndex(label)
+			if match == nil {
+				continue
+			}
+			expand := func(template string) string {
+				return string(rule.Pattern.ExpandString(nil, template, label, match))
+			}
+			if result.priority == "" && rule.Priority != "" {
+				result.priority = expand(rule.Priority)
+			}
+			for _, component := range rule.Components {
+				result.components = appendMissing(result.components, expand(component))
+			}
+			for _, version := range rule.FixVersions {
+				result.fixVersions = appendMissing(result.fixVersions, expand(version))
+			}
+			for _, field := range rule.Fields {
+				if !setFields[field.ID] {
+					setFields[field.ID] = true
+					field.Value = expand(field.Value)
+					result.fields = append(result.fields, field)
+				}
+			}
+		}
+	}
+	return result
+}
+
+func appendMissing(values []string, value string) []string {
+	if containsString(values, value) {
+		return values
+	}
+	return append(values, value)
+}
+
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+// jiraValue returns the value of the field as expected by the Jira API
+func (f LabelRuleField) jiraValue() (interface{}, error) {
+	switch f.Type {
+	case "number":
+		v, err := strconv.ParseFloat(f.Value, 64)
+		return v, errors.Wrapf(err, "invalid number %q for field %q", f.Value, f.ID)
+	case "option":
+		return map[string]interface{}{"value": f.Value}, nil
+	case "array":
+		return []interface{}{map[string]interface{}{"value": f.Value}}, nil
+	default:
+		return f.Value, nil
+	}
+}
+
+// fieldValueStrings flattens a field value as returned or expected by the Jira API in order to compare it
+func fieldValueStrings(v interface{}) []string {
+	switch value := v.(type) {
+	case nil:
+		return nil
+	case string:
+		return []string{value}
+	case float64:
+		return []string{strconv.FormatFloat(value, 'f', -1, 64)}
+	case map[string]interface{}:
+		for _, key := range []string{"value", "name", "id"} {
+			if s, ok := value[key].(string); ok {
+				return []string{s}
+			}
+		}
+		return nil
+	case []interface{}:
+		values := make([]string, 0, len(value))
+		for _, item := range value {
+			values = append(values, fieldValueStrings(item)...)
+		}
+		sort.Strings(values)
+		return values
+	default:
+		return nil
+	}
+}
+
+// issueComponents returns the default components and the ones added by label rules
+func (s *Sync) issueComponents(result labelRulesResult) []string {
+	components := append([]string(nil), s.DefaultJiraComponents...)
+	for _, component := range result.components {
+		components = appendMissing(components, component)
+	}
+	return components
+}
+
+// obsoleteRuleComponents returns the components added by label rules during the previous synchronization of the
+// issue that are not part of the given components anymore. Previous components are recorded in the state, if
+// there is no state components are never removed.
+func (s *Sync) obsoleteRuleComponents(zhIssue *zenhub.Issue, components []string) []string {
+	if s.State == nil {
+		return nil
+	}
+	st, _ := s.State.GetIssue(zhIssue.GetID())
+	var obsolete []string
+	for _, component := range st.RuleComponents {
+		if !containsString(components, component) {
+			obsolete = append(obsolete, component)
+		}
+	}
+	return obsolete
+}
+
+// labelRulesFields returns the priority and custom fields values set by label rules as expected by the Jira API,
+// invalid values are logged and ignored
+func (s *Sync) labelRulesFields(result labelRulesResult) map[string]interface{} {
+	fields := make(map[string]interface{}, len(result.fields)+1)
+	if result.priority != "" {
+		fields["priority"] = map[string]interface{}{"name": result.priority}
+	}
+	for _, f := range result.fields {
+		v, err := f.jiraValue()
+		if err != nil {
+			s.logger().Warnf("Ignoring label rule value: %v", err)
+			continue
+		}
+		fields[f.ID] = v
+	}
+	return fields
+}
+
+// checkLabelRules sets the priority and custom fields values of the update of a Jira issue if they differ from the
+// ones set by label rules, it returns true in this case
+func (s *Sync) checkLabelRules(result labelRulesResult, jiraIssue, resultIssue *jiralib.Issue) bool {
+	var updated bool
+	for id, v := range s.labelRulesFields(result) {
+		var current interface{}
+		if id == "priority" {
+			if jiraIssue.Fields.Priority != nil {
+				current = jiraIssue.Fields.Priority.Name
+			}
+		} else {
+			current = jiraIssue.Fields.Unknowns[id]
+		}
+		if strings.Join(fieldValueStrings(current), "\n") != strings.Join(fieldValueStrings(v), "\n") {
+			updated = true
+			resultIssue.Fields.Unknowns[id] = v
+		}
+	}
+	return updated
+}
+
+// getVersionsIDs returns the IDs of the Jira versions with the given names, unknown versions are logged and ignored
+func (s *Sync) getVersionsIDs(names []string) ([]string, error) {
+	if len(names) == 0 {
+		return nil, nil
+	}
+	s.versionsLock.Lock()
+	defer s.versionsLock.Unlock()
+	var reloaded bool
+	ids := make([]string, 0, len(names))
+	for _, name := range names {
+		id, ok := s.versionsIDs[name]
+		if !ok && !reloaded {
+			// Versions may have been created since the last lookup
+			versions, err := s.JiraClient.GetProjectVersions()
+			if err != nil {
+				return nil, err
+			}
+			reloaded = true
+			s.versionsIDs = make(map[string]string, len(versions))
+			for _, version := range versions {
+				s.versionsIDs[version.Name] = version.ID
+			}
+			id, ok = s.versionsIDs[name]
+		}
+		if !ok {
+			s.logger().Warnf("Ignoring unknown Jira version %q set by a label rule", name)
+			continue
+		}
+		ids = append(ids, id)
+	}
+	return ids, nil
+}
@@ -0,0 +1,97 @@
+package pkg
+
+import (
+	"reflect"
+	"regexp"
+	"testing"
+
+	jiralib "github.com/andygrunwald/go-jira"
+)
+
+func TestApplyLabelRules(t *testing.T) {
+	rules := []LabelRule{
+		{Pattern: regexp.MustCompile(`^priority/(.*)$`), Priority: "$1"},
+		{Pattern: regexp.MustCompile(`^bug$`), Priority: "High", Components: []string{"Core"}},
+		{Pattern: regexp.MustCompile(`^area/(.*)$`), Components: []string{"$1"}},
+		{Pattern: regexp.MustCompile(`^release/(.*)$`), FixVersions: []string{"v$1"}},
+		{Pattern: regexp.MustCompile(`^team/(?P<team>.*)$`), Fields: []LabelRuleField{{ID: "customfield_1", Type: "option", Value: "${team}"}}},
+		{Pattern: regexp.MustCompile(`^team-`), Fields: []LabelRuleField{{ID: "customfield_1", Type: "option", Value: "Other"}}},
+	}
+	tests := []struct {
+		name     string
+		labels   []string
+		expected labelRulesResult
+	}{
+		{"NoMatch", []string{"question"}, labelRulesResult{}},
+		{"FirstRuleWins", []string{"bug", "priority/Low"}, labelRulesResult{priority: "Low", components: []string{"Core"}}},
+		{"ComponentsUnion", []string{"area/UI", "bug", "area/Core"}, labelRulesResult{priority: "High", components: []string{"Core", "UI"}}},
+		{"FirstLabelWins", []string{"team/b", "team/a", "team-c"}, labelRulesResult{fields: []LabelRuleField{{ID: "customfield_1", Type: "option", Value: "a"}}}},
+		{"FixVersions", []string{"release/1.0"}, labelRulesResult{fixVersions: []string{"v1.0"}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := applyLabelRules(rules, tt.labels); !reflect.DeepEqual(got, tt.expected) {
+				t.Errorf("applyLabelRules(%v) = %+v, expecting %+v", tt.labels, got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestLabelRuleFieldJiraValue(t *testing.T) {
+	tests := []struct {
+		name     string
+		field    LabelRuleField
+		current  interface{}
+		expected []string
+	}{
+		{"Text", LabelRuleField{Type: "string", Value: "a"}, "a", []string{"a"}},
+		{"Number", LabelRuleField{Type: "number", Value: "3"}, float64(3), []string{"3"}},
+		{"Option", LabelRuleField{Type: "option", Value: "a"}, map[string]interface{}{"id": "10", "value": "a"}, []string{"a"}},
+		{"Array", LabelRuleField{Type: "array", Value: "a"}, []interface{}{map[string]interface{}{"id": "10", "value": "a"}}, []string{"a"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			v, err := tt.field.jiraValue()
+			if err != nil {
+				t.Fatalf("jiraValue() unexpected error: %v", err)
+			}
+			if got := fieldValueStrings(v); !reflect.DeepEqual(got, tt.expected) {
+				t.Errorf("fieldValueStrings(%v) = %v, expecting %v", v, got, tt.expected)
+			}
+			if got := fieldValueStrings(tt.current); !reflect.DeepEqual(got, tt.expected) {
+				t.Errorf("fieldValueStrings(%v) = %v, expecting %v", tt.current, got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestCheckComponentsOnIssue(t *testing.T) {
+	tests := []struct {
+		name       string
+		current    []string
+		components []string
+		obsolete   []string
+		expected   []string
+		updated    bool
+	}{
+		{"Unchanged", []string{"Core", "Manual"}, []string{"Core"}, nil, []string{"Core", "Manual"}, false},
+		{"Added", []string{"Manual"}, []string{"Core"}, nil, []string{"Manual", "Core"}, true},
+		{"ObsoleteRemoved", []string{"UI", "Manual"}, []string{"Core"}, []string{"UI"}, []string{"Manual", "Core"}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			issue := &jiralib.Issue{Fields: &jiralib.IssueFields{}}
+			for _, name := range tt.current {
+				issue.Fields.Components = append(issue.Fields.Components, &jiralib.Component{Name: name})
+			}
+			updated := new(Sync).checkComponentsOnIssue(issue, tt.components, tt.obsolete)
+			var got []string
+			for _, comp := range issue.Fields.Components {
+				got = append(got, comp.Name)
+			}
+			if !reflect.DeepEqual(got, tt.expected) || updated != tt.updated {
+				t.Errorf("checkComponentsOnIssue() = %v, %v, expecting %v, %v", got, updated, tt.expected, tt.updated)
+			}
+		})
+	}
+}
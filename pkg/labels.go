@@ -0,0 +1,122 @@
+package pkg
+
+import (
+	"regexp"
+	"sort"
+	"strings"
+
+	jiralib "github.com/andygrunwald/go-jira"
+
+	"github.com/ystia/zenhub-jira-sync/pkg/clients/zenhub"
+)
+
+// maxJiraLabelLength is the maximum length of a Jira label
+const maxJiraLabelLength = 255
+
+// NativeLabels defines how GitHub labels are synchronized as Jira labels.
+//
+// Labels added by the synchronization are the ones starting with Prefix if not empty, otherwise the ones recorded
+// in the synchronization state by the previous synchronization of the issue. Other Jira labels are left untouched.
+// Without Prefix nor State, labels removed from GitHub issues are not removed from Jira issues.
+type NativeLabels struct {
+	// Prefix is added to Jira labels, such as "gh:"
+	Prefix string
+	// InvalidCharacters matches characters of GitHub labels replaced by Replacement, it defaults to white spaces
+	InvalidCharacters *regexp.Regexp
+	// Replacement replaces invalid characters
+	Replacement string
+}
+
+var defaultLabelInvalidCharacters = regexp.MustCompile(`\s+`)
+
+// jiraLabel returns the Jira label of a GitHub label, an empty string is returned if nothing is left once sanitized
+func (nl *NativeLabels) jiraLabel(ghLabel string) string {
+	re := nl.InvalidCharacters
+	if re == nil {
+		re = defaultLabelInvalidCharacters
+	}
+	label := strings.TrimSpace(re.ReplaceAllString(strings.TrimSpace(ghLabel), nl.Replacement))
+	// Jira labels never contain white spaces whatever the replacement is
+	label = strings.Join(strings.Fields(label), "_")
+	if label == "" {
+		return ""
+	}
+	label = nl.Prefix + label
+	if runes := []rune(label); len(runes) > maxJiraLabelLength {
+		label = string(runes[:maxJiraLabelLength])
+	}
+	return label
+}
+
+// jiraLabels returns the sorted Jira labels of the given GitHub labels
+func (nl *NativeLabels) jiraLabels(ghLabels []string) []string {
+	labels := make([]string, 0, len(ghLabels))
+	known := make(map[string]bool, len(ghLabels))
+	for _, ghLabel := range ghLabels {
+		label := nl.jiraLabel(ghLabel)
+		if label != "" && !known[label] {
+			known[label] = true
+			labels = append(labels, label)
+		}
+	}
+	sort.Strings(labels)
+	return labels
+}
+
+// isManaged returns true if the given Jira label was added by the synchronization
+func (nl *NativeLabels) isManaged(label string, writtenLabels map[string]bool) bool {
+	if nl.Prefix != "" {
+		return strings.HasPrefix(label, nl.Prefix)
+	}
+	return writtenLabels[label]
+}
+
+// diffLabels returns the Jira labels of an issue once GitHub labels are synchronized, the returned boolean is true
+// if they changed. writtenLabels are the Jira labels written by the previous synchronization of the issue.
+func (nl *NativeLabels) diffLabels(jiraLabels, writtenLabels, ghLabels []string) ([]string, bool) {
+	written := make(map[string]bool, len(writtenLabels))
+	for _, label := range writtenLabels {
+		written[label] = true
+	}
+	expected := nl.jiraLabels(ghLabels)
+	wanted := make(map[string]bool, len(expected))
+	for _, label := range expected {
+		wanted[label] = true
+	}
+	result := make([]string, 0, len(jiraLabels)+len(expected))
+	present := make(map[string]bool, len(jiraLabels))
+	var changed bool
+	for _, label := range jiraLabels {
+		present[label] = true
+		if !wanted[label] && nl.isManaged(label, written) {
+			changed = true
+			continue
+		}
+		result = append(result, label)
+	}
+	for _, label := range expected {
+		if !present[label] {
+			changed = true
+			result = append(result, label)
+		}
+	}
+	return result, changed
+}
+
+// checkNativeLabels sets the labels of the update of a Jira issue if they changed, it returns true in this case
+func (s *Sync) checkNativeLabels(zhIssue *zenhub.Issue, jiraIssue, resultIssue *jiralib.Issue) bool {
+	if s.NativeLabels == nil {
+		return false
+	}
+	var writtenLabels []string
+	if s.State != nil {
+		st, _ := s.State.GetIssue(zhIssue.GetID())
+		writtenLabels = st.JiraLabels
+	}
+	labels, changed := s.NativeLabels.diffLabels(jiraIssue.Fields.Labels, writtenLabels, getZHIssueLabels(zhIssue))
+	if changed {
+		// Use an unknown field as the Labels field is omitted when empty
+		resultIssue.Fields.Unknowns["labels"] = labels
+	}
+	return changed
+}
@@ -0,0 +1,56 @@
+package pkg
+
+import (
+	"reflect"
+	"regexp"
+	"testing"
+)
+
+func TestNativeLabelsJiraLabel(t *testing.T) {
+	tests := []struct {
+		name     string
+		nl       NativeLabels
+		ghLabel  string
+		expected string
+	}{
+		{"Spaces", NativeLabels{Replacement: "_"}, "good first issue", "good_first_issue"},
+		{"Prefix", NativeLabels{Prefix: "gh:", Replacement: "-"}, " help  wanted ", "gh:help-wanted"},
+		{"CustomRule", NativeLabels{InvalidCharacters: regexp.MustCompile(`[^a-z0-9]+`), Replacement: "-"}, "kind/bug", "kind-bug"},
+		{"SpacesReplacement", NativeLabels{Replacement: " "}, "good first issue", "good_first_issue"},
+		{"Empty", NativeLabels{Prefix: "gh:", Replacement: "_"}, "  ", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.nl.jiraLabel(tt.ghLabel); got != tt.expected {
+				t.Errorf("jiraLabel(%q) = %q, expecting %q", tt.ghLabel, got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestNativeLabelsDiffLabels(t *testing.T) {
+	tests := []struct {
+		name          string
+		prefix        string
+		jiraLabels    []string
+		writtenLabels []string
+		ghLabels      []string
+		expected      []string
+		changed       bool
+	}{
+		{"Unchanged", "", []string{"team-a", "bug"}, []string{"bug"}, []string{"bug"}, []string{"team-a", "bug"}, false},
+		{"AddAndRemove", "", []string{"team-a", "good_first_issue"}, []string{"good_first_issue"}, []string{"bug"}, []string{"team-a", "bug"}, true},
+		{"KeepManualLabel", "", []string{"bug"}, nil, []string{}, []string{"bug"}, false},
+		{"KeepManualLabelMatchingWords", "", []string{"area_backend", "backend", "area"}, []string{"area_backend"}, []string{}, []string{"backend", "area"}, true},
+		{"Prefix", "gh:", []string{"gh:old", "team-a"}, nil, []string{"help wanted"}, []string{"team-a", "gh:help_wanted"}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			nl := &NativeLabels{Prefix: tt.prefix, Replacement: "_"}
+			labels, changed := nl.diffLabels(tt.jiraLabels, tt.writtenLabels, tt.ghLabels)
+			if !reflect.DeepEqual(labels, tt.expected) || changed != tt.changed {
+				t.Errorf("diffLabels() = %v, %v, expecting %v, %v", labels, changed, tt.expected, tt.changed)
+			}
+		})
+	}
+}
@@ -0,0 +1,57 @@
+// Package logging configures the logrus logger used by the synchronization.
+//
+// Log entries are a message with fields, such as the synchronized repository, the GitHub issue number or the Jira
+// issue key, written as text, logfmt or JSON lines.
+package logging
+
+import (
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+)
+
+// Log formats
+const (
+	// TextFormat writes human readable lines, colored when writing to a terminal
+	TextFormat = "text"
+	// LogfmtFormat writes key=value pairs
+	LogfmtFormat = "logfmt"
+	// JSONFormat writes JSON objects
+	JSONFormat = "json"
+)
+
+// ParseLevel returns the level with the given name, an empty name is the info level
+func ParseLevel(name string) (logrus.Level, error) {
+	if name == "" {
+		return logrus.InfoLevel, nil
+	}
+	level, err := logrus.ParseLevel(name)
+	return level, errors.Wrapf(err, "unsupported log level %q", name)
+}
+
+// NewFormatter returns the formatter of the given log format, an empty format is the text format
+func NewFormatter(format string) (logrus.Formatter, error) {
+	switch format {
+	case "", TextFormat:
+		return &logrus.TextFormatter{FullTimestamp: true}, nil
+	case LogfmtFormat:
+		return &logrus.TextFormatter{DisableColors: true, FullTimestamp: true}, nil
+	case JSONFormat:
+		return &logrus.JSONFormatter{}, nil
+	}
+	return nil, errors.Errorf("unsupported log format %q, expecting one of %s, %s or %s", format, TextFormat, LogfmtFormat, JSONFormat)
+}
+
+// Configure sets the format and the level of the given logger
+func Configure(logger *logrus.Logger, format, level string) error {
+	l, err := ParseLevel(level)
+	if err != nil {
+		return err
+	}
+	formatter, err := NewFormatter(format)
+	if err != nil {
+		return err
+	}
+	logger.SetLevel(l)
+	logger.SetFormatter(formatter)
+	return nil
+}
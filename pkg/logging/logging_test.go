@@ -0,0 +1,71 @@
+package logging
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+)
+
+func TestLoggerFormats(t *testing.T) {
+	tests := []struct {
+		name     string
+		format   string
+		expected string
+	}{
+		{"Logfmt", LogfmtFormat, `level=warning msg="failed to sync" error="not found" issue=12 repo=ystia/yorc`},
+		{"JSON", JSONFormat, `{"error":"not found","issue":12,"level":"warning","msg":"failed to sync","repo":"ystia/yorc"}`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var b bytes.Buffer
+			logger := logrus.New()
+			logger.SetOutput(&b)
+			err := Configure(logger, tt.format, "info")
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			// Disable timestamps to get reproducible entries
+			switch f := logger.Formatter.(type) {
+			case *logrus.TextFormatter:
+				f.DisableTimestamp = true
+			case *logrus.JSONFormatter:
+				f.DisableTimestamp = true
+			}
+			logger.WithField("repo", "ystia/yorc").WithField("issue", 12).WithError(errors.New("not found")).Warnf("failed to sync")
+			if got := strings.TrimSuffix(b.String(), "\n"); got != tt.expected {
+				t.Errorf("got %s, expecting %s", got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestConfigure(t *testing.T) {
+	tests := []struct {
+		name          string
+		format        string
+		level         string
+		expectedLevel logrus.Level
+		expectedError bool
+	}{
+		{"Defaults", "", "", logrus.InfoLevel, false},
+		{"Warning", TextFormat, "warning", logrus.WarnLevel, false},
+		{"Debug", JSONFormat, "DEBUG", logrus.DebugLevel, false},
+		{"UnsupportedLevel", TextFormat, "verbose", logrus.InfoLevel, true},
+		{"UnsupportedFormat", "xml", "info", logrus.InfoLevel, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			logger := logrus.New()
+			err := Configure(logger, tt.format, tt.level)
+			if (err != nil) != tt.expectedError {
+				t.Fatalf("Configure() error = %v, expecting error %v", err, tt.expectedError)
+			}
+			if logger.GetLevel() != tt.expectedLevel {
+				t.Errorf("level = %v, expecting %v", logger.GetLevel(), tt.expectedLevel)
+			}
+		})
+	}
+}
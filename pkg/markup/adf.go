@@ -0,0 +1,155 @@
+package markup
+
+import (
+	"strings"
+)
+
+// ADFNode is a node of an Atlassian Document Format document as used by the Jira Cloud REST API v3
+type ADFNode struct {
+	Type    string                 `json:"type"`
+	Version int                    `json:"version,omitempty"`
+	Attrs   map[string]interface{} `json:"attrs,omitempty"`
+	Content []*ADFNode             `json:"content,omitempty"`
+	Text    string                 `json:"text,omitempty"`
+	Marks   []*ADFMark             `json:"marks,omitempty"`
+}
+
+// ADFMark is a text formatting of an Atlassian Document Format text node
+type ADFMark struct {
+	Type  string                 `json:"type"`
+	Attrs map[string]interface{} `json:"attrs,omitempty"`
+}
+
+// ToADF converts GitHub-flavored Markdown into an Atlassian Document Format document
+func ToADF(markdown string) *ADFNode {
+	return &ADFNode{Type: "doc", Version: 1, Content: adfBlocks(parse(markdown))}
+}
+
+func adfBlocks(blocks []*block) []*ADFNode {
+	nodes := make([]*ADFNode, 0, len(blocks))
+	for _, bl := range blocks {
+		nodes = append(nodes, adfBlock(bl))
+	}
+	return nodes
+}
+
+func adfBlock(bl *block) *ADFNode {
+	switch bl.kind {
+	case headingBlock:
+		return &ADFNode{Type: "heading", Attrs: map[string]interface{}{"level": bl.level}, Content: adfInlines(bl.inlines, nil)}
+	case codeBlock:
+		n := &ADFNode{Type: "codeBlock"}
+		if bl.language != "" {
+			n.Attrs = map[string]interface{}{"language": bl.language}
+		}
+		if bl.code != "" {
+			n.Content = []*ADFNode{{Type: "text", Text: bl.code}}
+		}
+		return n
+	case quoteBlock:
+		return &ADFNode{Type: "blockquote", Content: adfBlocks(bl.children)}
+	case listBlock:
+		return adfList(bl)
+	case tableBlock:
+		table := &ADFNode{Type: "table", Content: []*ADFNode{adfTableRow(bl.header, "tableHeader")}}
+		for _, row := range bl.rows {
+			table.Content = append(table.Content, adfTableRow(row, "tableCell"))
+		}
+		return table
+	case ruleBlock:
+		return &ADFNode{Type: "rule"}
+	default:
+		return &ADFNode{Type: "paragraph", Content: adfInlines(bl.inlines, nil)}
+	}
+}
+
+func adfList(list *block) *ADFNode {
+	n := &ADFNode{Type: "bulletList"}
+	if list.ordered {
+		n.Type = "orderedList"
+	}
+	for _, item := range list.items {
+		inlines := item.inlines
+		if item.checked != nil {
+			box := "☐ "
+			if *item.checked {
+				box = "☑ "
+			}
+			inlines = append([]*inline{{kind: textInline, text: box}}, inlines...)
+		}
+		listItem := &ADFNode{Type: "listItem", Content: []*ADFNode{{Type: "paragraph", Content: adfInlines(inlines, nil)}}}
+		for _, sublist := range item.sublists {
+			listItem.Content = append(listItem.Content, adfList(sublist))
+		}
+		n.Content = append(n.Content, listItem)
+	}
+	return n
+}
+
+func adfTableRow(cells [][]*inline, cellType string) *ADFNode {
+	row := &ADFNode{Type: "tableRow"}
+	for _, cell := range cells {
+		row.Content = append(row.Content, &ADFNode{Type: cellType, Content: []*ADFNode{{Type: "paragraph", Content: adfInlines(cell, nil)}}})
+	}
+	return row
+}
+
+// adfInlines converts inline elements into text nodes carrying the given marks
+func adfInlines(inlines []*inline, marks []*ADFMark) []*ADFNode {
+	var nodes []*ADFNode
+	for _, in := range inlines {
+		switch in.kind {
+		case textInline:
+			nodes = append(nodes, adfText(in.text, marks)...)
+		case codeInline:
+			var codeMarks []*ADFMark
+			// Code marks may only be combined with links
+			for _, m := range marks {
+				if m.Type == "link" {
+					codeMarks = append(codeMarks, m)
+				}
+			}
+			nodes = append(nodes, adfText(in.text, append(codeMarks, &ADFMark{Type: "code"}))...)
+		case strongInline:
+			nodes = append(nodes, adfInlines(in.children, withMark(marks, &ADFMark{Type: "strong"}))...)
+		case emphasisInline:
+			nodes = append(nodes, adfInlines(in.children, withMark(marks, &ADFMark{Type: "em"}))...)
+		case strikeInline:
+			nodes = append(nodes, adfInlines(in.children, withMark(marks, &ADFMark{Type: "strike"}))...)
+		case linkInline:
+			link := &ADFMark{Type: "link", Attrs: map[string]interface{}{"href": in.url}}
+			nodes = append(nodes, adfInlines(in.children, withMark(marks, link))...)
+		case imageInline:
+			// External images are not inline nodes in ADF, link them instead
+			text := in.text
+			if text == "" {
+				text = in.url
+			}
+			link := &ADFMark{Type: "link", Attrs: map[string]interface{}{"href": in.url}}
+			nodes = append(nodes, adfText(text, withMark(marks, link))...)
+		case breakInline:
+			nodes = append(nodes, &ADFNode{Type: "hardBreak"})
+		}
+	}
+	return nodes
+}
+
+func adfText(text string, marks []*ADFMark) []*ADFNode {
+	var nodes []*ADFNode
+	for i, line := range strings.Split(text, "\n") {
+		if i > 0 {
+			nodes = append(nodes, &ADFNode{Type: "hardBreak"})
+		}
+		if line != "" {
+			nodes = append(nodes, &ADFNode{Type: "text", Text: line, Marks: marks})
+		}
+	}
+	return nodes
+}
+
+// withMark returns a copy of marks with the given mark added, so that sibling elements do not share it
+func withMark(marks []*ADFMark, mark *ADFMark) []*ADFMark {
+	result := make([]*ADFMark, 0, len(marks)+1)
+	result = append(result, marks...)
+	return append(result, mark)
+}
@@ -0,0 +1,69 @@
+// Package markup converts GitHub-flavored Markdown used in GitHub issues and comments into Jira formats.
+//
+// Jira Server and the REST API v2 use the Jira wiki markup (see ToJiraWiki) while the Jira Cloud REST API v3
+// uses the Atlassian Document Format (see ToADF).
+package markup
+
+import (
+	"strings"
+)
+
+// Equal returns true if both Jira wiki markup texts are the same once normalized the way Jira stores them
+//
+// Jira may change line endings and trailing spaces of stored texts, comparing them with Equal prevents
+// rewriting unchanged texts.
+func Equal(a, b string) bool {
+	return normalize(a) == normalize(b)
+}
+
+// Contains returns true if the Jira wiki markup text s contains the given substring once both are normalized
+func Contains(s, substr string) bool {
+	return strings.Contains(normalize(s), normalize(substr))
+}
+
+// Links returns URLs of images and links of the given GitHub-flavored Markdown, in order of appearance and
+// without duplicates
+func Links(markdown string) []string {
+	var links []string
+	seen := make(map[string]bool)
+	var walkInlines func(inlines []*inline)
+	walkInlines = func(inlines []*inline) {
+		for _, in := range inlines {
+			if (in.kind == linkInline || in.kind == imageInline) && !seen[in.url] {
+				seen[in.url] = true
+				links = append(links, in.url)
+			}
+			walkInlines(in.children)
+		}
+	}
+	var walkBlocks func(blocks []*block)
+	walkBlocks = func(blocks []*block) {
+		for _, bl := range blocks {
+			walkInlines(bl.inlines)
+			walkBlocks(bl.children)
+			for _, item := range bl.items {
+				walkInlines(item.inlines)
+				walkBlocks(item.sublists)
+			}
+			for _, cell := range bl.header {
+				walkInlines(cell)
+			}
+			for _, row := range bl.rows {
+				for _, cell := range row {
+					walkInlines(cell)
+				}
+			}
+		}
+	}
+	walkBlocks(parse(markdown))
+	return links
+}
+
+func normalize(s string) string {
+	s = strings.Replace(s, "\r\n", "\n", -1)
+	lines := strings.Split(s, "\n")
+	for i, l := range lines {
+		lines[i] = strings.TrimRight(l, " \t")
+	}
+	return strings.TrimSpace(strings.Join(lines, "\n"))
+}
@@ -0,0 +1,99 @@
+package markup
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func TestToJiraWiki(t *testing.T) {
+	tests := []struct {
+		name     string
+		markdown string
+		expected string
+	}{
+		{"Empty", "", ""},
+		{"Text", "Hello world", "Hello world"},
+		{"LineBreaks", "first line\r\nsecond line", "first line\nsecond line"},
+		{"Paragraphs", "first\n\n\nsecond", "first\n\nsecond"},
+		{"Headings", "# Title\n### Sub title ###", "h1. Title\n\nh3. Sub title"},
+		{"SetextHeadings", "Title\n=====\nSub\n---", "h1. Title\n\nh2. Sub"},
+		{"Emphasis", "**bold** __bold__ *italic* _italic_ ~~strike~~", "*bold* *bold* _italic_ _italic_ -strike-"},
+		{"NestedEmphasis", "**bold _italic_**", "*bold _italic_*"},
+		{"IntrawordUnderscores", "snake_case_name", "snake_case_name"},
+		{"CodeSpan", "run `go test ./...` now", "run {{go test ./...}} now"},
+		{"CodeSpanWithBackticks", "``a ` b``", "{{a ` b}}"},
+		{"Link", "see [the docs](https://example.com/docs \"title\")", "see [the docs|https://example.com/docs]"},
+		{"Autolink", "<https://example.com>", "[https://example.com]"},
+		{"Image", "![screenshot](https://example.com/a.png)", "!https://example.com/a.png!"},
+		{"EscapedMarkup", "a {macro} and [brackets] and \\*stars\\*", "a \\{macro\\} and \\[brackets\\] and \\*stars\\*"},
+		{"FencedCode", "```go\nfunc main() {\n\t*a = b_c\n}\n```", "{code:go}\nfunc main() {\n\t*a = b_c\n}\n{code}"},
+		{"FencedCodeUnknownLanguage", "~~~\nplain\n~~~", "{noformat}\nplain\n{noformat}"},
+		{"IndentedCode", "text\n\n    code\n      more", "text\n\n{noformat}\ncode\n  more\n{noformat}"},
+		{"Quote", "> quoted\n> **text**", "{quote}\nquoted\n*text*\n{quote}"},
+		{"Rule", "a\n\n***\n\nb", "a\n\n----\n\nb"},
+		{"BulletList", "- one\n* two\n+ three", "* one\n* two\n* three"},
+		{"OrderedList", "1. one\n2. two", "# one\n# two"},
+		{"NestedLists", "- one\n  1. sub one\n  2. sub two\n    - deep\n- two", "* one\n*# sub one\n*# sub two\n*#* deep\n* two"},
+		{"LooseList", "- one\n\n- two\n\nafter", "* one\n* two\n\nafter"},
+		{"ListContinuation", "- one\n  continued", "* one continued"},
+		{"TaskList", "- [x] done\n- [ ] todo", "* (/) done\n* (x) todo"},
+		{"Table", "| a | b |\n|---|:-:|\n| `x\\|y` | |\n| c | d |", "||a||b||\n|{{x\\|y}}| |\n|c|d|"},
+		{"ImageTag", `<img width="200" alt="shot" src="https://example.com/a.png">`, "!https://example.com/a.png!"},
+		{"HTMLComments", "<!-- template\nhint -->\nDescription <!-- inline --> here", "Description  here"},
+		{"HTMLCommentsInCode", "```\n<!-- kept -->\n```", "{noformat}\n<!-- kept -->\n{noformat}"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ToJiraWiki(tt.markdown); got != tt.expected {
+				t.Errorf("ToJiraWiki() = %q, expecting %q", got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestToJiraWikiWithAttachments(t *testing.T) {
+	markdown := "![shot](https://example.com/a.png) see [logs.zip](https://example.com/logs.zip) and [other](https://example.com)"
+	attachments := map[string]string{
+		"https://example.com/a.png":    "1234-a.png",
+		"https://example.com/logs.zip": "5678-logs.zip",
+	}
+	expected := "!1234-a.png! see [logs.zip|^5678-logs.zip] and [other|https://example.com]"
+	if got := ToJiraWikiWithAttachments(markdown, attachments); got != expected {
+		t.Errorf("ToJiraWikiWithAttachments() = %q, expecting %q", got, expected)
+	}
+}
+
+func TestLinks(t *testing.T) {
+	markdown := "[![img](https://example.com/a.png)](https://example.com)\n\n- <https://example.com>\n\n| h |\n|---|\n| [x](https://example.com/x) |"
+	expected := []string{"https://example.com", "https://example.com/a.png", "https://example.com/x"}
+	if got := Links(markdown); !reflect.DeepEqual(got, expected) {
+		t.Errorf("Links() = %q, expecting %q", got, expected)
+	}
+}
+
+func TestToADF(t *testing.T) {
+	doc := ToADF("# Title\n\nSome **bold [link](https://example.com)**\n\n- [x] done")
+	b, err := json.Marshal(doc)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := `{"type":"doc","version":1,"content":[` +
+		`{"type":"heading","attrs":{"level":1},"content":[{"type":"text","text":"Title"}]},` +
+		`{"type":"paragraph","content":[{"type":"text","text":"Some "},` +
+		`{"type":"text","text":"bold ","marks":[{"type":"strong"}]},` +
+		`{"type":"text","text":"link","marks":[{"type":"strong"},{"type":"link","attrs":{"href":"https://example.com"}}]}]},` +
+		`{"type":"bulletList","content":[{"type":"listItem","content":[{"type":"paragraph","content":[{"type":"text","text":"☑ "},{"type":"text","text":"done"}]}]}]}]}`
+	if string(b) != expected {
+		t.Errorf("ToADF() = %s\nexpecting %s", b, expected)
+	}
+}
+
+func TestEqual(t *testing.T) {
+	if !Equal("a  \r\nb\n", "a\nb") {
+		t.Error("expecting texts to be equal")
+	}
+	if Equal("a\nb", "a b") {
+		t.Error("expecting texts to be different")
+	}
+}
@@ -0,0 +1,593 @@
+package markup
+
+import (
+	"regexp"
+	"strings"
+	"unicode"
+)
+
+type blockKind int
+
+const (
+	paragraphBlock blockKind = iota
+	headingBlock
+	codeBlock
+	quoteBlock
+	listBlock
+	tableBlock
+	ruleBlock
+)
+
+// block is a GitHub-flavored Markdown block element
+type block struct {
+	kind blockKind
+	// level of headings
+	level int
+	// language and raw content of code blocks
+	language string
+	code     string
+	// inline content of paragraphs and headings
+	inlines []*inline
+	// children of quotes
+	children []*block
+	// items of lists
+	ordered bool
+	items   []*listItem
+	// cells of tables
+	header [][]*inline
+	rows   [][][]*inline
+}
+
+// listItem is an item of a list, it may contain nested lists
+type listItem struct {
+	inlines []*inline
+	// checked is not nil for task list items
+	checked  *bool
+	sublists []*block
+}
+
+type inlineKind int
+
+const (
+	textInline inlineKind = iota
+	codeInline
+	strongInline
+	emphasisInline
+	strikeInline
+	linkInline
+	imageInline
+	breakInline
+)
+
+// inline is a GitHub-flavored Markdown inline element
+type inline struct {
+	kind inlineKind
+	// text of text and code spans, alternative text of images
+	text string
+	// url of links and images
+	url      string
+	children []*inline
+}
+
+var (
+	headingRE        = regexp.MustCompile(`^ {0,3}(#{1,6})(?:[ \t]+(.*?))?(?:[ \t]+#+)?[ \t]*$`)
+	fenceRE          = regexp.MustCompile("^( {0,3})(`{3,}|~{3,})[ \t]*([^`\\s]*)")
+	ruleRE           = regexp.MustCompile(`^ {0,3}(?:(?:\*[ \t]*){3,}|(?:-[ \t]*){3,}|(?:_[ \t]*){3,})$`)
+	setextRE         = regexp.MustCompile(`^ {0,3}(=+|-+)[ \t]*$`)
+	listItemRE       = regexp.MustCompile(`^([ \t]*)([-*+]|\d{1,9}[.)])(?:[ \t]+(.*))?$`)
+	taskRE           = regexp.MustCompile(`^\[([ xX])\][ \t]+`)
+	quoteRE          = regexp.MustCompile(`^ {0,3}>[ ]?`)
+	imgTagRE         = regexp.MustCompile(`^<img\s[^>]*?\bsrc\s*=\s*["']([^"']+)["'][^>]*>`)
+	imgAltRE         = regexp.MustCompile(`\balt\s*=\s*["']([^"']*)["']`)
+	tableDelimiterRE = regexp.MustCompile(`^[ \t]*\|?[ \t]*:?-+:?[ \t]*(?:\|[ \t]*:?-+:?[ \t]*)*\|?[ \t]*$`)
+)
+
+// parse parses a GitHub-flavored Markdown document into blocks
+func parse(markdown string) []*block {
+	markdown = strings.Replace(markdown, "\r\n", "\n", -1)
+	markdown = strings.Replace(markdown, "\r", "\n", -1)
+	return parseBlocks(removeHTMLComments(strings.Split(markdown, "\n")))
+}
+
+// removeHTMLComments removes HTML comments, typically coming from issue templates, outside of code blocks
+func removeHTMLComments(lines []string) []string {
+	result := make([]string, 0, len(lines))
+	var fence string
+	var inComment bool
+	for _, line := range lines {
+		if fence != "" {
+			if strings.HasPrefix(strings.TrimSpace(line), fence) {
+				fence = ""
+			}
+			result = append(result, line)
+			continue
+		}
+		if !inComment {
+			if m := fenceRE.FindStringSubmatch(line); m != nil {
+				fence = m[2]
+				result = append(result, line)
+				continue
+			}
+		}
+		hadComment := inComment || strings.Contains(line, "<!--")
+		var b strings.Builder
+		for line != "" {
+			if inComment {
+				end := strings.Index(line, "-->")
+				if end < 0 {
+					line = ""
+					break
+				}
+				line = line[end+3:]
+				inComment = false
+				continue
+			}
+			start := strings.Index(line, "<!--")
+			if start < 0 {
+				b.WriteString(line)
+				break
+			}
+			b.WriteString(line[:start])
+			line = line[start+4:]
+			inComment = true
+		}
+		if hadComment && strings.TrimSpace(b.String()) == "" {
+			// Do not keep lines that only contained comments
+			continue
+		}
+		result = append(result, b.String())
+	}
+	return result
+}
+
+func isBlank(line string) bool {
+	return strings.TrimSpace(line) == ""
+}
+
+func indentation(line string) int {
+	var n int
+	for _, c := range line {
+		switch c {
+		case ' ':
+			n++
+		case '\t':
+			n += 4 - n%4
+		default:
+			return n
+		}
+	}
+	return n
+}
+
+// startsBlock returns true if the given line interrupts a paragraph
+func startsBlock(line string) bool {
+	return headingRE.MatchString(line) || fenceRE.MatchString(line) || ruleRE.MatchString(line) ||
+		quoteRE.MatchString(line) || listItemRE.MatchString(line)
+}
+
+func parseBlocks(lines []string) []*block {
+	var blocks []*block
+	for i := 0; i < len(lines); {
+		line := lines[i]
+		switch {
+		case isBlank(line):
+			i++
+		case fenceRE.MatchString(line):
+			var b *block
+			b, i = parseFencedCode(lines, i)
+			blocks = append(blocks, b)
+		case headingRE.MatchString(line):
+			m := headingRE.FindStringSubmatch(line)
+			blocks = append(blocks, &block{kind: headingBlock, level: len(m[1]), inlines: parseInlines(m[2])})
+			i++
+		case ruleRE.MatchString(line):
+			blocks = append(blocks, &block{kind: ruleBlock})
+			i++
+		case quoteRE.MatchString(line):
+			var quoted []string
+			for ; i < len(lines) && !isBlank(lines[i]); i++ {
+				l := lines[i]
+				if loc := quoteRE.FindStringIndex(l); loc != nil {
+					l = l[loc[1]:]
+				}
+				quoted = append(quoted, l)
+			}
+			blocks = append(blocks, &block{kind: quoteBlock, children: parseBlocks(quoted)})
+		case listItemRE.MatchString(line):
+			var b *block
+			b, i = parseList(lines, i)
+			blocks = append(blocks, b)
+		case indentation(line) >= 4:
+			var code []string
+			for ; i < len(lines) && (isBlank(lines[i]) || indentation(lines[i]) >= 4); i++ {
+				code = append(code, removeIndentation(lines[i], 4))
+			}
+			for len(code) > 0 && isBlank(code[len(code)-1]) {
+				code = code[:len(code)-1]
+			}
+			blocks = append(blocks, &block{kind: codeBlock, code: strings.Join(code, "\n")})
+		case strings.Contains(line, "|") && i+1 < len(lines) && tableDelimiterRE.MatchString(lines[i+1]) && strings.Contains(lines[i+1], "|"):
+			var b *block
+			b, i = parseTable(lines, i)
+			blocks = append(blocks, b)
+		default:
+			var paragraph []string
+			for ; i < len(lines) && !isBlank(lines[i]); i++ {
+				if len(paragraph) > 0 {
+					if m := setextRE.FindStringSubmatch(lines[i]); m != nil {
+						level := 1
+						if m[1][0] == '-' {
+							level = 2
+						}
+						blocks = append(blocks, &block{kind: headingBlock, level: level, inlines: parseInlines(strings.Join(paragraph, " "))})
+						paragraph = nil
+						i++
+						break
+					}
+					if startsBlock(lines[i]) {
+						break
+					}
+				}
+				paragraph = append(paragraph, strings.TrimSpace(lines[i]))
+			}
+			if len(paragraph) > 0 {
+				blocks = append(blocks, &block{kind: paragraphBlock, inlines: parseInlines(strings.Join(paragraph, "\n"))})
+			}
+		}
+	}
+	return blocks
+}
+
+// removeIndentation removes up to n columns of indentation from the given line
+func removeIndentation(line string, n int) string {
+	var col int
+	for i, c := range line {
+		if col >= n {
+			return line[i:]
+		}
+		switch c {
+		case ' ':
+			col++
+		case '\t':
+			col += 4 - col%4
+		default:
+			return line[i:]
+		}
+	}
+	return ""
+}
+
+func parseFencedCode(lines []string, i int) (*block, int) {
+	m := fenceRE.FindStringSubmatch(lines[i])
+	indent, fence := len(m[1]), m[2]
+	b := &block{kind: codeBlock, language: strings.ToLower(m[3])}
+	var code []string
+	for i++; i < len(lines); i++ {
+		trimmed := strings.TrimSpace(lines[i])
+		if strings.HasPrefix(trimmed, fence) && strings.Trim(trimmed, fence[:1]) == "" {
+			i++
+			break
+		}
+		code = append(code, removeIndentation(lines[i], indent))
+	}
+	b.code = strings.Join(code, "\n")
+	return b, i
+}
+
+func parseList(lines []string, i int) (*block, int) {
+	type level struct {
+		indent int
+		list   *block
+	}
+	var stack []level
+	var lastItem *listItem
+	for ; i < len(lines); i++ {
+		line := lines[i]
+		if isBlank(line) {
+			// A blank line ends the list unless it is followed by an item or an indented continuation
+			j := i + 1
+			for j < len(lines) && isBlank(lines[j]) {
+				j++
+			}
+			if j == len(lines) || (!listItemRE.MatchString(lines[j]) && indentation(lines[j]) < 2) {
+				break
+			}
+			continue
+		}
+		m := listItemRE.FindStringSubmatch(line)
+		if m == nil || ruleRE.MatchString(line) {
+			if fenceRE.MatchString(strings.TrimSpace(line)) || headingRE.MatchString(line) || ruleRE.MatchString(line) || quoteRE.MatchString(line) {
+				break
+			}
+			if i > 0 && isBlank(lines[i-1]) && indentation(line) < 2 {
+				break
+			}
+			// Continuation of the previous item, Jira list items are single lines
+			lastItem.inlines = append(lastItem.inlines, &inline{kind: textInline, text: " "})
+			lastItem.inlines = append(lastItem.inlines, parseInlines(strings.TrimSpace(line))...)
+			continue
+		}
+		indent := indentation(m[1])
+		ordered := m[2] != "-" && m[2] != "*" && m[2] != "+"
+		switch {
+		case len(stack) == 0:
+			stack = append(stack, level{indent: indent, list: &block{kind: listBlock, ordered: ordered}})
+		case indent >= stack[len(stack)-1].indent+2 && lastItem != nil:
+			sublist := &block{kind: listBlock, ordered: ordered}
+			lastItem.sublists = append(lastItem.sublists, sublist)
+			stack = append(stack, level{indent: indent, list: sublist})
+		default:
+			for len(stack) > 1 && indent < stack[len(stack)-1].indent {
+				stack = stack[:len(stack)-1]
+			}
+		}
+		lastItem = &listItem{}
+		text := m[3]
+		if tm := taskRE.FindStringSubmatch(text); tm != nil {
+			checked := tm[1] != " "
+			lastItem.checked = &checked
+			text = text[len(tm[0]):]
+		}
+		lastItem.inlines = parseInlines(strings.TrimSpace(text))
+		top := stack[len(stack)-1].list
+		top.items = append(top.items, lastItem)
+	}
+	return stack[0].list, i
+}
+
+func parseTable(lines []string, i int) (*block, int) {
+	b := &block{kind: tableBlock}
+	for _, cell := range splitTableRow(lines[i]) {
+		b.header = append(b.header, parseInlines(cell))
+	}
+	for i += 2; i < len(lines) && !isBlank(lines[i]) && strings.Contains(lines[i], "|"); i++ {
+		var row [][]*inline
+		for _, cell := range splitTableRow(lines[i]) {
+			row = append(row, parseInlines(cell))
+		}
+		b.rows = append(b.rows, row)
+	}
+	return b, i
+}
+
+// splitTableRow splits a table row on pipes that are neither escaped nor in a code span
+func splitTableRow(line string) []string {
+	line = strings.TrimSpace(line)
+	line = strings.TrimPrefix(line, "|")
+	if strings.HasSuffix(line, "|") && !strings.HasSuffix(line, "\\|") {
+		line = line[:len(line)-1]
+	}
+	var cells []string
+	var cell strings.Builder
+	var inCode bool
+	for i := 0; i < len(line); i++ {
+		c := line[i]
+		switch {
+		case c == '\\' && i+1 < len(line) && line[i+1] == '|':
+			cell.WriteByte('|')
+			i++
+			continue
+		case c == '`':
+			inCode = !inCode
+		case c == '|' && !inCode:
+			cells = append(cells, strings.TrimSpace(cell.String()))
+			cell.Reset()
+			continue
+		}
+		cell.WriteByte(c)
+	}
+	return append(cells, strings.TrimSpace(cell.String()))
+}
+
+// inlineParser parses inline elements of a paragraph
+type inlineParser struct {
+	s       string
+	text    strings.Builder
+	inlines []*inline
+}
+
+func parseInlines(s string) []*inline {
+	p := &inlineParser{s: s}
+	p.parse()
+	return p.inlines
+}
+
+func (p *inlineParser) flush() {
+	if p.text.Len() > 0 {
+		p.inlines = append(p.inlines, &inline{kind: textInline, text: p.text.String()})
+		p.text.Reset()
+	}
+}
+
+func (p *inlineParser) add(i *inline) {
+	p.flush()
+	p.inlines = append(p.inlines, i)
+}
+
+func (p *inlineParser) parse() {
+	s := p.s
+	for i := 0; i < len(s); {
+		c := s[i]
+		switch {
+		case c == '\\' && i+1 < len(s) && isASCIIPunct(s[i+1]):
+			p.text.WriteByte(s[i+1])
+			i += 2
+			continue
+		case c == '\n':
+			p.add(&inline{kind: breakInline})
+			i++
+			continue
+		case c == '`':
+			if n := p.codeSpan(i); n > 0 {
+				i += n
+				continue
+			}
+		case c == '!' && strings.HasPrefix(s[i:], "!["):
+			if text, url, n := parseLink(s[i+1:]); n > 0 {
+				p.add(&inline{kind: imageInline, text: text, url: url})
+				i += n + 1
+				continue
+			}
+		case c == '[':
+			if text, url, n := parseLink(s[i:]); n > 0 {
+				p.add(&inline{kind: linkInline, url: url, children: parseInlines(text)})
+				i += n
+				continue
+			}
+		case c == '<' && imgTagRE.MatchString(s[i:]):
+			// GitHub uses HTML image tags for pasted images
+			tag := imgTagRE.FindStringSubmatch(s[i:])
+			image := &inline{kind: imageInline, url: tag[1]}
+			if alt := imgAltRE.FindStringSubmatch(tag[0]); alt != nil {
+				image.text = alt[1]
+			}
+			p.add(image)
+			i += len(tag[0])
+			continue
+		case c == '<':
+			if end := strings.IndexByte(s[i:], '>'); end > 0 {
+				url := s[i+1 : i+end]
+				if isAutolink(url) {
+					p.add(&inline{kind: linkInline, url: url, children: []*inline{{kind: textInline, text: url}}})
+					i += end + 1
+					continue
+				}
+			}
+		case c == '*' || c == '_' || c == '~':
+			if n := p.delimited(i); n > 0 {
+				i += n
+				continue
+			}
+		}
+		p.text.WriteByte(c)
+		i++
+	}
+	p.flush()
+}
+
+// codeSpan parses a code span starting at i and returns its length or 0 if there is no code span
+func (p *inlineParser) codeSpan(i int) int {
+	s := p.s
+	n := 0
+	for i+n < len(s) && s[i+n] == '`' {
+		n++
+	}
+	delimiter := s[i : i+n]
+	for j := i + n; j < len(s); {
+		k := strings.Index(s[j:], delimiter)
+		if k < 0 {
+			break
+		}
+		end := j + k
+		if end+n < len(s) && s[end+n] == '`' {
+			// Longer backtick run, not a closing delimiter
+			for end < len(s) && s[end] == '`' {
+				end++
+			}
+			j = end
+			continue
+		}
+		code := strings.Replace(s[i+n:end], "\n", " ", -1)
+		if len(code) > 2 && code[0] == ' ' && code[len(code)-1] == ' ' && strings.TrimSpace(code) != "" {
+			code = code[1 : len(code)-1]
+		}
+		p.add(&inline{kind: codeInline, text: code})
+		return end + n - i
+	}
+	// Unmatched backticks are literal
+	p.text.WriteString(delimiter)
+	return n
+}
+
+// delimited parses emphasis, strong emphasis and strikethrough starting at i and returns its length or 0 if
+// there is no such element
+func (p *inlineParser) delimited(i int) int {
+	s := p.s
+	c := s[i]
+	kind := emphasisInline
+	delimiter := s[i : i+1]
+	switch {
+	case c == '~' && strings.HasPrefix(s[i:], "~~"):
+		kind, delimiter = strikeInline, "~~"
+	case c == '~':
+		return 0
+	case i+1 < len(s) && s[i+1] == c:
+		kind, delimiter = strongInline, s[i:i+2]
+	}
+	start := i + len(delimiter)
+	if start >= len(s) || isSpace(s[start]) {
+		return 0
+	}
+	if c == '_' && i > 0 && isAlnum(s[i-1]) {
+		// Intraword underscores are not emphasis
+		return 0
+	}
+	for j := start + 1; j <= len(s)-len(delimiter); j++ {
+		if !strings.HasPrefix(s[j:], delimiter) || isSpace(s[j-1]) {
+			continue
+		}
+		end := j + len(delimiter)
+		if end < len(s) && s[end] == c {
+			// Part of a longer delimiter run
+			continue
+		}
+		if c == '_' && end < len(s) && isAlnum(s[end]) {
+			continue
+		}
+		p.add(&inline{kind: kind, children: parseInlines(s[start:j])})
+		return end - i
+	}
+	return 0
+}
+
+// parseLink parses a [text](url) link and returns its text, url and length or a 0 length if there is no link
+func parseLink(s string) (string, string, int) {
+	depth := 0
+	closing := -1
+	for i := 0; i < len(s) && closing < 0; i++ {
+		switch s[i] {
+		case '\\':
+			i++
+		case '[':
+			depth++
+		case ']':
+			depth--
+			if depth == 0 {
+				closing = i
+			}
+		}
+	}
+	if closing < 0 || closing+1 >= len(s) || s[closing+1] != '(' {
+		return "", "", 0
+	}
+	end := strings.IndexByte(s[closing+1:], ')')
+	if end < 0 {
+		return "", "", 0
+	}
+	destination := strings.TrimSpace(s[closing+2 : closing+1+end])
+	if fields := strings.Fields(destination); len(fields) > 0 {
+		// Remove optional titles
+		destination = strings.Trim(fields[0], "<>")
+	}
+	if destination == "" {
+		return "", "", 0
+	}
+	return s[1:closing], destination, closing + 2 + end
+}
+
+func isAutolink(s string) bool {
+	return (strings.HasPrefix(s, "http://") || strings.HasPrefix(s, "https://") || strings.HasPrefix(s, "mailto:")) &&
+		!strings.ContainsAny(s, " \t\n<")
+}
+
+func isASCIIPunct(c byte) bool {
+	return c < unicode.MaxASCII && unicode.IsPunct(rune(c)) || c == '`' || c == '~' || c == '^' || c == '|' || c == '<' || c == '>' || c == '+' || c == '='
+}
+
+func isSpace(c byte) bool {
+	return c == ' ' || c == '\t' || c == '\n'
+}
+
+func isAlnum(c byte) bool {
+	return c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9'
+}
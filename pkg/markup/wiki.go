@@ -0,0 +1,223 @@
+package markup
+
+import (
+	"strings"
+)
+
+// jiraCodeLanguages maps Markdown code block languages to languages supported by the Jira code macro
+var jiraCodeLanguages = map[string]string{
+	"actionscript": "actionscript",
+	"ada":          "ada",
+	"applescript":  "applescript",
+	"bash":         "bash",
+	"sh":           "bash",
+	"shell":        "bash",
+	"console":      "bash",
+	"zsh":          "bash",
+	"c":            "c",
+	"c#":           "c#",
+	"csharp":       "c#",
+	"cs":           "c#",
+	"c++":          "c++",
+	"cpp":          "c++",
+	"css":          "css",
+	"erlang":       "erlang",
+	"go":           "go",
+	"golang":       "go",
+	"groovy":       "groovy",
+	"haskell":      "haskell",
+	"html":         "html",
+	"java":         "java",
+	"javascript":   "javascript",
+	"js":           "javascript",
+	"json":         "json",
+	"lua":          "lua",
+	"objc":         "objc",
+	"perl":         "perl",
+	"php":          "php",
+	"python":       "python",
+	"py":           "python",
+	"r":            "r",
+	"ruby":         "ruby",
+	"rb":           "ruby",
+	"sass":         "sass",
+	"scala":        "scala",
+	"sql":          "sql",
+	"swift":        "swift",
+	"vb":           "visualbasic",
+	"xml":          "xml",
+	"yaml":         "yaml",
+	"yml":          "yaml",
+}
+
+// ToJiraWiki converts GitHub-flavored Markdown into Jira wiki markup
+func ToJiraWiki(markdown string) string {
+	return ToJiraWikiWithAttachments(markdown, nil)
+}
+
+// ToJiraWikiWithAttachments converts GitHub-flavored Markdown into Jira wiki markup, images and links whose URL
+// is a key of attachments are rewritten to reference the Jira attachment with the associated file name.
+func ToJiraWikiWithAttachments(markdown string, attachments map[string]string) string {
+	w := &wikiWriter{attachments: attachments}
+	w.writeBlocks(parse(markdown))
+	return w.String()
+}
+
+// wikiWriter renders Markdown elements as Jira wiki markup
+type wikiWriter struct {
+	strings.Builder
+	attachments map[string]string
+}
+
+func (w *wikiWriter) writeBlocks(blocks []*block) {
+	for i, bl := range blocks {
+		if i > 0 {
+			w.WriteString("\n\n")
+		}
+		w.writeBlock(bl)
+	}
+}
+
+func (w *wikiWriter) writeBlock(bl *block) {
+	switch bl.kind {
+	case paragraphBlock:
+		w.writeInlines(bl.inlines)
+	case headingBlock:
+		w.WriteString("h")
+		w.WriteByte(byte('0' + bl.level))
+		w.WriteString(". ")
+		w.writeInlines(bl.inlines)
+	case codeBlock:
+		if language, ok := jiraCodeLanguages[bl.language]; ok {
+			w.WriteString("{code:" + language + "}\n")
+			w.WriteString(bl.code)
+			w.WriteString("\n{code}")
+		} else {
+			w.WriteString("{noformat}\n")
+			w.WriteString(bl.code)
+			w.WriteString("\n{noformat}")
+		}
+	case quoteBlock:
+		w.WriteString("{quote}\n")
+		w.writeBlocks(bl.children)
+		w.WriteString("\n{quote}")
+	case listBlock:
+		w.writeList(bl, "")
+	case tableBlock:
+		w.writeTableRow(bl.header, "||")
+		for _, row := range bl.rows {
+			w.WriteString("\n")
+			w.writeTableRow(row, "|")
+		}
+	case ruleBlock:
+		w.WriteString("----")
+	}
+}
+
+func (w *wikiWriter) writeList(list *block, prefix string) {
+	if list.ordered {
+		prefix += "#"
+	} else {
+		prefix += "*"
+	}
+	for i, item := range list.items {
+		if i > 0 {
+			w.WriteString("\n")
+		}
+		w.WriteString(prefix + " ")
+		if item.checked != nil {
+			if *item.checked {
+				w.WriteString("(/) ")
+			} else {
+				w.WriteString("(x) ")
+			}
+		}
+		w.writeInlines(item.inlines)
+		for _, sublist := range item.sublists {
+			w.WriteString("\n")
+			w.writeList(sublist, prefix)
+		}
+	}
+}
+
+func (w *wikiWriter) writeTableRow(cells [][]*inline, separator string) {
+	w.WriteString(separator)
+	for _, cell := range cells {
+		if len(cell) == 0 {
+			// Jira ignores empty cells
+			w.WriteString(" ")
+		}
+		w.writeInlines(cell)
+		w.WriteString(separator)
+	}
+}
+
+func (w *wikiWriter) writeInlines(inlines []*inline) {
+	for _, in := range inlines {
+		switch in.kind {
+		case textInline:
+			w.WriteString(escapeWiki(in.text))
+		case codeInline:
+			if in.text != "" {
+				w.WriteString("{{" + escapeWiki(in.text) + "}}")
+			}
+		case strongInline:
+			w.writeEffect("*", in.children)
+		case emphasisInline:
+			w.writeEffect("_", in.children)
+		case strikeInline:
+			w.writeEffect("-", in.children)
+		case linkInline:
+			text := &wikiWriter{attachments: w.attachments}
+			text.writeInlines(in.children)
+			target := in.url
+			if filename, ok := w.attachments[in.url]; ok {
+				target = "^" + filename
+			}
+			w.WriteString("[")
+			if text.Len() > 0 && text.String() != escapeWiki(in.url) && text.String() != escapeWiki(target) {
+				w.WriteString(text.String() + "|")
+			}
+			w.WriteString(target + "]")
+		case imageInline:
+			if filename, ok := w.attachments[in.url]; ok {
+				w.WriteString("!" + filename + "!")
+			} else {
+				w.WriteString("!" + in.url + "!")
+			}
+		case breakInline:
+			w.WriteString("\n")
+		}
+	}
+}
+
+func (w *wikiWriter) writeEffect(effect string, children []*inline) {
+	w.WriteString(effect)
+	w.writeInlines(children)
+	w.WriteString(effect)
+}
+
+// escapeWiki escapes characters of text that Jira would otherwise interpret as markup
+func escapeWiki(text string) string {
+	var b strings.Builder
+	for i := 0; i < len(text); i++ {
+		c := text[i]
+		switch c {
+		case '{', '}', '[', ']', '|':
+			b.WriteByte('\\')
+		case '*', '_', '-', '+', '^', '~':
+			// Text effects apply on words bounded by those characters
+			prevBoundary := i == 0 || isWikiBoundary(text[i-1])
+			nextBoundary := i == len(text)-1 || isWikiBoundary(text[i+1])
+			if prevBoundary != nextBoundary {
+				b.WriteByte('\\')
+			}
+		}
+		b.WriteByte(c)
+	}
+	return b.String()
+}
+
+func isWikiBoundary(c byte) bool {
+	return !isAlnum(c) && c < 0x80
+}
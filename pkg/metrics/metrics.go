@@ -0,0 +1,143 @@
+// Package metrics exposes synchronization metrics to Prometheus using the default registry.
+package metrics
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+// Backends names used as backend label
+const (
+	BackendGitHub = "github"
+	BackendZenHub = "zenhub"
+	BackendJira   = "jira"
+)
+
+const namespace = "zenhub_jira_sync"
+
+var (
+	apiCalls = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "api_calls_total",
+		Help:      "Number of API calls by backend, API method and status (success or error).",
+	}, []string{"backend", "method", "status"})
+	apiCallsDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "api_call_duration_seconds",
+		Help:      "Duration of API calls by backend and API method, including retries.",
+		Buckets:   []float64{0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10, 30, 60},
+	}, []string{"backend", "method"})
+	httpRequests = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "http_requests_total",
+		Help:      "Number of HTTP requests sent to backends by backend, HTTP method and status code.",
+	}, []string{"backend", "method", "code"})
+	rateLimitRemaining = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "rate_limit_remaining",
+		Help:      "Number of requests remaining in the current rate limit window of backends.",
+	}, []string{"backend"})
+	syncDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "sync_duration_seconds",
+		Help:      "Duration of synchronizations runs by repository.",
+		Buckets:   []float64{10, 30, 60, 120, 300, 600, 1200, 1800, 3600},
+	}, []string{"repository"})
+	syncRuns = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "sync_runs_total",
+		Help:      "Number of synchronizations runs by repository and status (success or error).",
+	}, []string{"repository", "status"})
+	syncItems = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "sync_items_total",
+		Help:      "Number of synchronized items by repository, entity and outcome.",
+	}, []string{"repository", "entity", "outcome"})
+	lastSuccess = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "last_success_timestamp_seconds",
+		Help:      "Time of the last successful synchronization run by repository, as UTC epoch seconds.",
+	}, []string{"repository"})
+)
+
+func status(err error) string {
+	if err != nil {
+		return "error"
+	}
+	return "success"
+}
+
+// ObserveAPICall records a call to the given API method of a backend.
+//
+// It matches the OnCall function of instrumented clients once the backend is bound.
+func ObserveAPICall(backend, method string, duration time.Duration, err error) {
+	apiCalls.WithLabelValues(backend, method, status(err)).Inc()
+	apiCallsDuration.WithLabelValues(backend, method).Observe(duration.Seconds())
+}
+
+// ObserveSync records a synchronization run of a repository that failed if err is not nil, items are the numbers of
+// synchronized items indexed by entity and outcome
+func ObserveSync(repository string, startedAt, finishedAt time.Time, err error, items map[string]map[string]int) {
+	syncDuration.WithLabelValues(repository).Observe(finishedAt.Sub(startedAt).Seconds())
+	syncRuns.WithLabelValues(repository, status(err)).Inc()
+	if err == nil {
+		lastSuccess.WithLabelValues(repository).Set(float64(finishedAt.Unix()))
+	}
+	for entity, outcomes := range items {
+		for outcome, count := range outcomes {
+			syncItems.WithLabelValues(repository, entity, outcome).Add(float64(count))
+		}
+	}
+}
+
+// Transport is an http.RoundTripper counting requests sent to a backend and tracking its rate limit
+type Transport struct {
+	Backend string
+	// Base is the transport used to actually perform requests, http.DefaultTransport is used if nil
+	Base http.RoundTripper
+}
+
+// NewTransport creates a Transport for the given backend on top of the given base transport
+func NewTransport(backend string, base http.RoundTripper) *Transport {
+	return &Transport{Backend: backend, Base: base}
+}
+
+// RoundTrip executes a single HTTP transaction and records its status code
+func (t *Transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	base := t.Base
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	resp, err := base.RoundTrip(req)
+	if err != nil {
+		httpRequests.WithLabelValues(t.Backend, req.Method, "error").Inc()
+		return resp, err
+	}
+	httpRequests.WithLabelValues(t.Backend, req.Method, strconv.Itoa(resp.StatusCode)).Inc()
+	if remaining, ok := parseRateLimitRemaining(resp.Header); ok {
+		rateLimitRemaining.WithLabelValues(t.Backend).Set(float64(remaining))
+	}
+	return resp, nil
+}
+
+// parseRateLimitRemaining returns the number of remaining requests in the rate limit window.
+//
+// GitHub uses X-RateLimit-Remaining, ZenHub X-RateLimit-Used and X-RateLimit-Limit.
+func parseRateLimitRemaining(header http.Header) (int, bool) {
+	if remaining, err := strconv.Atoi(header.Get("X-RateLimit-Remaining")); err == nil {
+		return remaining, true
+	}
+	used, err := strconv.Atoi(header.Get("X-RateLimit-Used"))
+	if err != nil {
+		return 0, false
+	}
+	limit, err := strconv.Atoi(header.Get("X-RateLimit-Limit"))
+	if err != nil {
+		return 0, false
+	}
+	return limit - used, true
+}
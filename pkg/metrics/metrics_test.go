@@ -0,0 +1,74 @@
+package metrics
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+func TestObserveSync(t *testing.T) {
+	startedAt := time.Date(2020, 5, 4, 10, 0, 0, 0, time.UTC)
+	finishedAt := startedAt.Add(time.Minute)
+	items := map[string]map[string]int{"issues": {"created": 2, "failed": 1}}
+	ObserveSync("ystia/yorc", startedAt, finishedAt, nil, items)
+	ObserveSync("ystia/yorc", startedAt, finishedAt.Add(time.Minute), errors.New("failure"), items)
+
+	if v := testutil.ToFloat64(syncRuns.WithLabelValues("ystia/yorc", "success")); v != 1 {
+		t.Errorf("successful runs = %v, expecting 1", v)
+	}
+	if v := testutil.ToFloat64(syncRuns.WithLabelValues("ystia/yorc", "error")); v != 1 {
+		t.Errorf("failed runs = %v, expecting 1", v)
+	}
+	if v := testutil.ToFloat64(lastSuccess.WithLabelValues("ystia/yorc")); v != float64(finishedAt.Unix()) {
+		t.Errorf("last success = %v, expecting the end of the successful run %v", v, finishedAt.Unix())
+	}
+	if v := testutil.ToFloat64(syncItems.WithLabelValues("ystia/yorc", "issues", "created")); v != 4 {
+		t.Errorf("created issues = %v, expecting 4", v)
+	}
+}
+
+func TestTransport(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-RateLimit-Remaining", "42")
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer server.Close()
+
+	client := &http.Client{Transport: NewTransport("test", nil)}
+	resp, err := client.Get(server.URL)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	resp.Body.Close()
+	if v := testutil.ToFloat64(httpRequests.WithLabelValues("test", "GET", "404")); v != 1 {
+		t.Errorf("requests = %v, expecting 1", v)
+	}
+	if v := testutil.ToFloat64(rateLimitRemaining.WithLabelValues("test")); v != 42 {
+		t.Errorf("remaining requests = %v, expecting 42", v)
+	}
+}
+
+func TestParseRateLimitRemaining(t *testing.T) {
+	tests := []struct {
+		name      string
+		header    http.Header
+		remaining int
+		ok        bool
+	}{
+		{"GitHub", http.Header{"X-Ratelimit-Remaining": {"4990"}}, 4990, true},
+		{"ZenHub", http.Header{"X-Ratelimit-Used": {"20"}, "X-Ratelimit-Limit": {"100"}}, 80, true},
+		{"None", http.Header{}, 0, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			remaining, ok := parseRateLimitRemaining(tt.header)
+			if remaining != tt.remaining || ok != tt.ok {
+				t.Errorf("parseRateLimitRemaining() = %d, %v, expecting %d, %v", remaining, ok, tt.remaining, tt.ok)
+			}
+		})
+	}
+}
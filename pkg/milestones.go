@@ -2,10 +2,11 @@ package pkg
 
 import (
 	"context"
-	"log"
 	"time"
 
 	jiralib "github.com/andygrunwald/go-jira"
+	gh "github.com/google/go-github/v24/github"
+
 	"github.com/ystia/zenhub-jira-sync/pkg/clients/zenhub"
 )
 
@@ -47,46 +48,74 @@ func (s *Sync) diffMilestoneAndSprint(m *zenhub.Milestone, sprint *jiralib.Sprin
 }
 
 func (s *Sync) milestones(ctx context.Context) error {
-	log.Print("Listing milestones")
+	s.logger().Infof("Listing milestones")
 	ghMilestones, err := s.GithubClient.ListMilestones(ctx)
 	if err != nil {
 		return err
 	}
 
-	log.Print("Listing Spints")
+	s.logger().Infof("Listing Spints")
 	jiraSprints, err := s.JiraClient.ListSprints(ctx)
 	if err != nil {
 		return err
 	}
 
-	log.Print("Comparing milestones and sprints")
+	s.logger().Infof("Comparing milestones and sprints")
 	for _, m := range ghMilestones {
-		msExists := false
-		milestone, err := s.ZenhubClient.DecorateGHMilestone(m)
+		err = s.checkMilestone(m, jiraSprints)
 		if err != nil {
 			return err
 		}
-		for _, sprint := range jiraSprints {
-			if *m.Title == sprint.Name {
-				msExists = true
-				if s.diffMilestoneAndSprint(milestone, &sprint) {
-					s.JiraClient.UpdateSprint(&sprint)
-				}
+	}
+
+	return nil
+}
+
+// checkMilestone creates or updates the Jira sprint matching the given GitHub milestone
+func (s *Sync) checkMilestone(m *gh.Milestone, jiraSprints []jiralib.Sprint) error {
+	var outcomes itemOutcomes
+	err := s.syncMilestone(m, jiraSprints, &outcomes)
+	s.Report.add(ReportEntitySprints, outcomes, err)
+	return err
+}
+
+// syncMilestone creates or updates the Jira sprint matching the given GitHub milestone, changes applied to the
+// sprint are recorded into outcomes
+func (s *Sync) syncMilestone(m *gh.Milestone, jiraSprints []jiralib.Sprint, outcomes *itemOutcomes) error {
+	msExists := false
+	milestone, err := s.ZenhubClient.DecorateGHMilestone(m)
+	if err != nil {
+		return err
+	}
+	for _, sprint := range jiraSprints {
+		if *m.Title == sprint.Name {
+			msExists = true
+			if s.diffMilestoneAndSprint(milestone, &sprint) {
+				outcomes.updated = true
+				s.JiraClient.UpdateSprint(&sprint)
 			}
 		}
-		// Create only new milestones
-		if !msExists && *milestone.State != "closed" {
-			sprint, err := s.JiraClient.CreateSprint(*milestone.Title, "", milestone.StartDate, milestone.DueOn)
-			if err != nil {
-				return err
-			}
-			if milestone.StartDate != nil && (*milestone.StartDate).Before(time.Now()) {
-				sprint.State = "active"
-				s.JiraClient.UpdateSprint(sprint)
-			}
+	}
+	// Create only new milestones
+	if !msExists && *milestone.State != "closed" {
+		sprint, err := s.JiraClient.CreateSprint(*milestone.Title, "", milestone.StartDate, milestone.DueOn)
+		if err != nil {
+			return err
+		}
+		outcomes.created = true
+		if milestone.StartDate != nil && (*milestone.StartDate).Before(time.Now()) {
+			sprint.State = "active"
+			s.JiraClient.UpdateSprint(sprint)
 		}
-
 	}
-
 	return nil
 }
+
+// Milestone synchronizes a single GitHub milestone with its Jira sprint
+func (s *Sync) Milestone(ctx context.Context, m *gh.Milestone) error {
+	jiraSprints, err := s.JiraClient.ListSprints(ctx)
+	if err != nil {
+		return err
+	}
+	return s.checkMilestone(m, jiraSprints)
+}
@@ -0,0 +1,199 @@
+package pkg
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+
+	jiralib "github.com/andygrunwald/go-jira"
+	gh "github.com/google/go-github/v24/github"
+	"github.com/pkg/errors"
+
+	"github.com/ystia/zenhub-jira-sync/pkg/clients/jira"
+	"github.com/ystia/zenhub-jira-sync/pkg/markup"
+)
+
+// orphanCommentPrefix starts comments added to orphan issues, it allows to not comment them twice
+const orphanCommentPrefix = "This issue is no longer synchronized with GitHub"
+
+// OrphanIssues defines how open Jira issues whose GitHub issue was transferred, deleted or converted to a
+// discussion are handled. Orphan issues are always reported in logs.
+type OrphanIssues struct {
+	// Label is added to orphan issues if not empty
+	Label string
+	// Comment enables adding a comment giving the reason why the issue is orphan
+	Comment bool
+	// Transition is the name of a transition applied to orphan issues if not empty
+	Transition string
+	// JQL is a JQL clause selecting the Jira issues of this repository such as "component = backend", it avoids
+	// looking at remote links of issues of other repositories when the Jira project is shared
+	JQL string
+}
+
+// checkOrphanIssues looks for open Jira issues of this repository that do not match a GitHub issue anymore.
+//
+// liveIssues are the IDs of GitHub issues known to exist, they are not checked again.
+func (s *Sync) checkOrphanIssues(ctx context.Context, liveIssues map[int64]bool, errs *issuesErrorsCollector) error {
+	if s.JiraClient.GetCustomFieldID(jira.CFNameGitHubNumber) == "" {
+		s.logger().Warnf("Jira issues orphan of their GitHub issue can not be checked without the GitHub Number field")
+		return nil
+	}
+	s.logger().Infof("Checking Jira issues orphan of their GitHub issue")
+	repo, err := s.GithubClient.GetRepository(ctx)
+	if err != nil {
+		return err
+	}
+	jiraIssues, err := s.JiraClient.ListOpenIssuesWithGithubID(s.OrphanIssues.JQL)
+	if err != nil {
+		return err
+	}
+	candidates := make([]*jiralib.Issue, 0)
+	for i := range jiraIssues {
+		ghID, _ := getJiraIssueGithubRef(s.JiraClient, &jiraIssues[i])
+		if !liveIssues[ghID] {
+			candidates = append(candidates, &jiraIssues[i])
+		}
+	}
+	s.forEach(len(candidates), func(i int) {
+		_, number := getJiraIssueGithubRef(s.JiraClient, candidates[i])
+		errs.add(IssueRef{Number: number}, s.checkOrphanIssue(ctx, repo, candidates[i]))
+	})
+	return nil
+}
+
+// checkOrphanIssue applies the orphans handling to the given Jira issue if it belongs to the given repository
+// and does not match a GitHub issue anymore
+func (s *Sync) checkOrphanIssue(ctx context.Context, repo *gh.Repository, jiraIssue *jiralib.Issue) error {
+	ghID, number := getJiraIssueGithubRef(s.JiraClient, jiraIssue)
+	// The project may be shared by several repositories, issues of this repository are linked to it
+	links, err := s.JiraClient.GetIssueRemoteLinks(jiraIssue.Key)
+	if err != nil {
+		return err
+	}
+	issueURL := fmt.Sprintf("%s/issues/%d", repo.GetHTMLURL(), number)
+	var own bool
+	for _, link := range links {
+		if link.Object.Title == "Original GitHub Issue" && strings.EqualFold(link.Object.URL, issueURL) {
+			own = true
+			break
+		}
+	}
+	if !own {
+		return nil
+	}
+
+	reason, err := s.getOrphanReason(ctx, repo, ghID, number)
+	if err != nil || reason == "" {
+		return err
+	}
+	logger := s.logger().WithField("issue", number).WithField("jira_key", jiraIssue.Key)
+	logger.Warnf("Jira issue is orphan: %s", reason)
+
+	var outcomes itemOutcomes
+	err = s.handleOrphanIssue(jiraIssue, reason, &outcomes)
+	s.Report.add(ReportEntityIssues, outcomes, err)
+	return err
+}
+
+// handleOrphanIssue applies the configured actions to an orphan issue, changes are recorded into outcomes
+func (s *Sync) handleOrphanIssue(jiraIssue *jiralib.Issue, reason string, outcomes *itemOutcomes) error {
+	if s.OrphanIssues.Label != "" && !hasJiraLabel(jiraIssue, s.OrphanIssues.Label) {
+		err := s.JiraClient.AddIssueLabel(jiraIssue.Key, s.OrphanIssues.Label)
+		if err != nil {
+			return err
+		}
+		outcomes.updated = true
+	}
+	if s.OrphanIssues.Comment && !hasJiraComment(jiraIssue, orphanCommentPrefix) {
+		_, err := s.JiraClient.AddComment(jiraIssue.Key, withSyncCommentMarker(fmt.Sprintf("%s: %s.", orphanCommentPrefix, reason)))
+		if err != nil {
+			return err
+		}
+		outcomes.commented = true
+	}
+	if s.OrphanIssues.Transition != "" {
+		transitions, err := s.JiraClient.GetIssueTransitions(jiraIssue.Key)
+		if err != nil {
+			return err
+		}
+		for _, t := range transitions {
+			if t.Name == s.OrphanIssues.Transition {
+				err = s.JiraClient.TransitionIssue(jiraIssue.Key, t.Name)
+				if err != nil {
+					return err
+				}
+				outcomes.transitioned = true
+				return nil
+			}
+		}
+		s.logger().WithField("jira_key", jiraIssue.Key).Warnf("Transition %q is not available for orphan issue, ignoring it", s.OrphanIssues.Transition)
+	}
+	return nil
+}
+
+// getOrphanReason returns why the GitHub issue with the given ID and number does not exist anymore in the given
+// repository, or an empty string if it still exists
+func (s *Sync) getOrphanReason(ctx context.Context, repo *gh.Repository, ghID int64, number int) (string, error) {
+	ref := fmt.Sprintf("GitHub issue %s#%d", repo.GetFullName(), number)
+	ghIssue, err := s.GithubClient.GetIssue(ctx, number)
+	if err != nil {
+		if errResp, ok := errors.Cause(err).(*gh.ErrorResponse); ok && errResp.Response != nil {
+			switch errResp.Response.StatusCode {
+			case http.StatusGone:
+				return ref + " was deleted", nil
+			case http.StatusNotFound:
+				return ref + " was deleted or converted to a discussion", nil
+			}
+		}
+		return "", err
+	}
+	switch {
+	case strings.Contains(ghIssue.GetHTMLURL(), "/discussions/"):
+		return fmt.Sprintf("%s was converted to discussion %s", ref, ghIssue.GetHTMLURL()), nil
+	case !strings.EqualFold(ghIssue.GetRepositoryURL(), repo.GetURL()):
+		// GitHub redirects transferred issues to their new repository
+		return fmt.Sprintf("%s was transferred to %s", ref, ghIssue.GetHTMLURL()), nil
+	case ghIssue.GetID() != ghID:
+		return fmt.Sprintf("%s now refers to another issue", ref), nil
+	}
+	return "", nil
+}
+
+// getJiraIssueGithubRef returns the GitHub ID and number stored in custom fields of a Jira issue
+func getJiraIssueGithubRef(jiraClient jira.API, jiraIssue *jiralib.Issue) (int64, int) {
+	var ghID int64
+	var number int
+	if jiraIssue.Fields == nil {
+		return ghID, number
+	}
+	// Numbers are decoded as float64 from JSON
+	if v, ok := jiraIssue.Fields.Unknowns[jiraClient.GetCustomFieldID(jira.CFNameGitHubID)].(float64); ok {
+		ghID = int64(v)
+	}
+	if v, ok := jiraIssue.Fields.Unknowns[jiraClient.GetCustomFieldID(jira.CFNameGitHubNumber)].(float64); ok {
+		number = int(v)
+	}
+	return ghID, number
+}
+
+func hasJiraLabel(jiraIssue *jiralib.Issue, label string) bool {
+	for _, l := range jiraIssue.Fields.Labels {
+		if l == label {
+			return true
+		}
+	}
+	return false
+}
+
+func hasJiraComment(jiraIssue *jiralib.Issue, prefix string) bool {
+	if jiraIssue.Fields.Comments == nil {
+		return false
+	}
+	for _, c := range jiraIssue.Fields.Comments.Comments {
+		if c != nil && markup.Contains(c.Body, prefix) {
+			return true
+		}
+	}
+	return false
+}
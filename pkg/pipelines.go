@@ -0,0 +1,48 @@
+package pkg
+
+import (
+	"strings"
+
+	jiralib "github.com/andygrunwald/go-jira"
+
+	"github.com/ystia/zenhub-jira-sync/pkg/clients/zenhub"
+)
+
+func (s *Sync) getPipelineToStatus(pipelineName string) *PipelineToStatus {
+	for i := range s.PipelinesToStatuses {
+		if strings.EqualFold(s.PipelinesToStatuses[i].Pipeline, pipelineName) {
+			return &s.PipelinesToStatuses[i]
+		}
+	}
+	return nil
+}
+
+// checkPipelineStatus transitions the Jira issue to the status mapped to the ZenHub pipeline of the issue if they disagree
+//
+// It returns true if the issue reached the mapped status.
+func (s *Sync) checkPipelineStatus(zhIssue *zenhub.Issue, jiraIssue *jiralib.Issue) (bool, error) {
+	if zhIssue.Pipeline == nil {
+		return false, nil
+	}
+	p2s := s.getPipelineToStatus(zhIssue.Pipeline.Name)
+	if p2s == nil {
+		return false, nil
+	}
+	var status *jiralib.Status
+	if jiraIssue.Fields != nil {
+		status = jiraIssue.Fields.Status
+	}
+	if status == nil {
+		// Status is unknown for newly created issues
+		var err error
+		status, err = s.JiraClient.GetIssueStatus(jiraIssue.Key)
+		if err != nil || status == nil {
+			return false, err
+		}
+	}
+	if p2s.Transition.isTarget(status) {
+		return false, nil
+	}
+	reached, err := s.applyStatusTransition(jiraIssue.Key, status, &p2s.Transition)
+	return reached != nil, err
+}
@@ -0,0 +1,95 @@
+package pkg
+
+import (
+	"strings"
+	"testing"
+
+	jiralib "github.com/andygrunwald/go-jira"
+	"github.com/pkg/errors"
+
+	"github.com/ystia/zenhub-jira-sync/pkg/clients/jira"
+	"github.com/ystia/zenhub-jira-sync/pkg/clients/zenhub"
+)
+
+// fakeWorkflowJira is a Jira API with a single issue moving through a workflow
+type fakeWorkflowJira struct {
+	jira.API
+	status string
+	// transitions are available transitions indexed by lower-cased status name
+	transitions map[string][]jiralib.Transition
+	applied     []string
+}
+
+func (f *fakeWorkflowJira) GetIssueStatus(issueKeyOrID string) (*jiralib.Status, error) {
+	return &jiralib.Status{Name: f.status}, nil
+}
+
+func (f *fakeWorkflowJira) GetIssueTransitions(issueKeyOrID string) ([]jiralib.Transition, error) {
+	return f.transitions[strings.ToLower(f.status)], nil
+}
+
+func (f *fakeWorkflowJira) TransitionIssueWithFields(issueKeyOrID, transitionName string, fields map[string]interface{}) error {
+	for _, t := range f.transitions[strings.ToLower(f.status)] {
+		if t.Name == transitionName {
+			f.status = t.To.Name
+			f.applied = append(f.applied, t.Name)
+			return nil
+		}
+	}
+	return errors.Errorf("transition %q is not available", transitionName)
+}
+
+func newFakeWorkflowJira(status string) *fakeWorkflowJira {
+	to := func(name string) jiralib.Status {
+		return jiralib.Status{Name: name}
+	}
+	return &fakeWorkflowJira{
+		status: status,
+		transitions: map[string][]jiralib.Transition{
+			"backlog":     {{ID: "1", Name: "Start", To: to("In Progress")}},
+			"in progress": {{ID: "2", Name: "Review", To: to("In Review")}, {ID: "3", Name: "Stop", To: to("Backlog")}},
+			"in review":   {{ID: "4", Name: "Done", To: to("Done")}},
+		},
+	}
+}
+
+func TestCheckPipelineStatus(t *testing.T) {
+	tests := []struct {
+		name                 string
+		status               string
+		knownStatus          bool
+		transition           StatusTransition
+		expectedTransitioned bool
+		expectedStatus       string
+		expectedApplied      []string
+	}{
+		{"AlreadyInStatus", "In Progress", true, StatusTransition{Statuses: []string{"in progress"}}, false, "In Progress", nil},
+		{"Walk", "Backlog", true, StatusTransition{Statuses: []string{"In Review"}}, true, "In Review", []string{"Start", "Review"}},
+		{"NewIssue", "Backlog", false, StatusTransition{Statuses: []string{"In Progress"}}, true, "In Progress", []string{"Start"}},
+		{"NamedTransitions", "Backlog", true, StatusTransition{Statuses: []string{"In Review"}, Transitions: []string{"start", "Done", "review"}}, true, "In Review", []string{"Start", "Review"}},
+		{"TransitionsExhausted", "Backlog", true, StatusTransition{Statuses: []string{"Done"}, Transitions: []string{"Start"}}, false, "In Progress", []string{"Start"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fake := newFakeWorkflowJira(tt.status)
+			s := &Sync{
+				JiraClient:          fake,
+				PipelinesToStatuses: []PipelineToStatus{{Pipeline: "Dev", Transition: tt.transition}},
+			}
+			jiraIssue := &jiralib.Issue{Key: "PRJ-1"}
+			if tt.knownStatus {
+				jiraIssue.Fields = &jiralib.IssueFields{Status: &jiralib.Status{Name: tt.status}}
+			}
+			transitioned, err := s.checkPipelineStatus(&zenhub.Issue{Pipeline: &zenhub.IssueDataPipeline{Name: "dev"}}, jiraIssue)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if transitioned != tt.expectedTransitioned || fake.status != tt.expectedStatus {
+				t.Errorf("checkPipelineStatus() = %v with status %q, expecting %v with status %q", transitioned, fake.status, tt.expectedTransitioned, tt.expectedStatus)
+			}
+			if strings.Join(fake.applied, ",") != strings.Join(tt.expectedApplied, ",") {
+				t.Errorf("applied transitions %v, expecting %v", fake.applied, tt.expectedApplied)
+			}
+		})
+	}
+}
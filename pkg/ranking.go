@@ -0,0 +1,126 @@
+package pkg
+
+import (
+	"sort"
+
+	jiralib "github.com/andygrunwald/go-jira"
+
+	"github.com/ystia/zenhub-jira-sync/pkg/clients/jira"
+	"github.com/ystia/zenhub-jira-sync/pkg/clients/zenhub"
+)
+
+// rankedIssue is a Jira issue key associated to its current Jira rank
+//
+// The rank is empty if unknown (for instance for newly created issues)
+type rankedIssue struct {
+	key  string
+	rank string
+}
+
+// rankMove is a set of issues that should be ranked, in this order, either before or after a given issue
+type rankMove struct {
+	issues []string
+	before string
+	after  string
+}
+
+// sortIssuesByPosition sorts ZenHub issues of a pipeline by their position, issues without position are kept at the end
+func sortIssuesByPosition(issues []*zenhub.Issue) {
+	sort.SliceStable(issues, func(i, j int) bool {
+		if issues[i].Position == nil || issues[j].Position == nil {
+			return issues[i].Position != nil && issues[j].Position == nil
+		}
+		return *issues[i].Position < *issues[j].Position
+	})
+}
+
+func (s *Sync) getRankedIssue(jiraIssue *jiralib.Issue) rankedIssue {
+	ri := rankedIssue{key: jiraIssue.Key}
+	if jiraIssue.Fields != nil {
+		if rank, ok := jiraIssue.Fields.Unknowns[s.JiraClient.GetCustomFieldID(jira.CFNameRank)].(string); ok {
+			ri.rank = rank
+		}
+	}
+	return ri
+}
+
+// rankIssues ranks Jira issues in the given order
+func (s *Sync) rankIssues(pipelineName string, issues []rankedIssue) error {
+	for _, move := range computeRankMoves(issues) {
+		s.logger().WithField("pipeline", pipelineName).Infof("Ranking issues %v", move.issues)
+		err := s.JiraClient.RankIssues(move.issues, move.before, move.after)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// computeRankMoves returns a minimal set of moves to apply on Jira issues to rank them in the given order.
+//
+// Issues that are part of the longest sequence already ranked in the expected order are not moved.
+// Issues with an unknown rank are considered as ranked after all others, in the given order.
+func computeRankMoves(issues []rankedIssue) []rankMove {
+	if len(issues) < 2 {
+		return nil
+	}
+	// Compute the current position of each issue in Jira
+	order := make([]int, len(issues))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(i, j int) bool {
+		ri, rj := issues[order[i]].rank, issues[order[j]].rank
+		if ri == "" || rj == "" {
+			return ri != "" && rj == ""
+		}
+		return ri < rj
+	})
+	currentPos := make([]int, len(issues))
+	for pos, idx := range order {
+		currentPos[idx] = pos
+	}
+
+	// Longest increasing subsequence of current positions in the expected order
+	lengths := make([]int, len(issues))
+	previous := make([]int, len(issues))
+	lisEnd := 0
+	for i := range issues {
+		lengths[i] = 1
+		previous[i] = -1
+		for j := 0; j < i; j++ {
+			if currentPos[j] < currentPos[i] && lengths[j]+1 > lengths[i] {
+				lengths[i] = lengths[j] + 1
+				previous[i] = j
+			}
+		}
+		if lengths[i] > lengths[lisEnd] {
+			lisEnd = i
+		}
+	}
+	inPlace := make([]bool, len(issues))
+	firstInPlace := lisEnd
+	for i := lisEnd; i >= 0; i = previous[i] {
+		inPlace[i] = true
+		firstInPlace = i
+	}
+
+	// Group consecutive issues to move
+	moves := make([]rankMove, 0)
+	for i := 0; i < len(issues); i++ {
+		if inPlace[i] {
+			continue
+		}
+		move := rankMove{}
+		if i == 0 {
+			move.before = issues[firstInPlace].key
+		} else {
+			move.after = issues[i-1].key
+		}
+		for ; i < len(issues) && !inPlace[i]; i++ {
+			move.issues = append(move.issues, issues[i].key)
+		}
+		moves = append(moves, move)
+	}
+	return moves
+}
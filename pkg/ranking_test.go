@@ -0,0 +1,41 @@
+package pkg
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestComputeRankMoves(t *testing.T) {
+	tests := []struct {
+		name   string
+		issues []rankedIssue
+		want   []rankMove
+	}{
+		{"Empty", nil, nil},
+		{"Single", []rankedIssue{{"A-1", "a"}}, nil},
+		{"AlreadyRanked", []rankedIssue{{"A-1", "a"}, {"A-2", "b"}, {"A-3", "c"}}, []rankMove{}},
+		{"NewIssuesAtTheEnd", []rankedIssue{{"A-1", "a"}, {"A-2", ""}, {"A-3", ""}}, []rankMove{}},
+		{"FirstMoved", []rankedIssue{{"A-3", "c"}, {"A-1", "a"}, {"A-2", "b"}}, []rankMove{
+			{issues: []string{"A-3"}, before: "A-1"},
+		}},
+		{"LastMoved", []rankedIssue{{"A-2", "b"}, {"A-3", "c"}, {"A-1", "a"}}, []rankMove{
+			{issues: []string{"A-1"}, after: "A-3"},
+		}},
+		{"NewIssueOnTop", []rankedIssue{{"A-3", ""}, {"A-1", "a"}, {"A-2", "b"}}, []rankMove{
+			{issues: []string{"A-3"}, before: "A-1"},
+		}},
+		{"GroupedMoves", []rankedIssue{{"A-1", "a"}, {"A-5", "e"}, {"A-4", "d"}, {"A-2", "b"}, {"A-3", "c"}}, []rankMove{
+			{issues: []string{"A-5", "A-4"}, after: "A-1"},
+		}},
+		{"Reversed", []rankedIssue{{"A-3", "c"}, {"A-2", "b"}, {"A-1", "a"}}, []rankMove{
+			{issues: []string{"A-2", "A-1"}, after: "A-3"},
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := computeRankMoves(tt.issues); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("computeRankMoves() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
@@ -1,8 +1,6 @@
 package pkg
 
 import (
-	"log"
-
 	"github.com/ystia/zenhub-jira-sync/pkg/clients/jira"
 	"github.com/ystia/zenhub-jira-sync/pkg/clients/zenhub"
 )
@@ -12,7 +10,7 @@ func (s *Sync) releases() ([]releasesTuple, error) {
 	if err != nil {
 		return nil, err
 	}
-	log.Print("Listing ZenHub Releases Reports")
+	s.logger().Infof("Listing ZenHub Releases Reports")
 	zhReleases, err := s.ZenhubClient.GetReleasesReports()
 
 	if err != nil {
@@ -28,24 +26,28 @@ func (s *Sync) releases() ([]releasesTuple, error) {
 	for _, release := range zhReleases {
 		rExists := false
 		if !s.ReleaseNameRE.MatchString(release.Title) {
-			log.Printf("Ignoring ZenHub release %q that does not match the release name pattern", release.Title)
+			s.logger().WithField("release", release.Title).Debugf("Ignoring ZenHub release that does not match the release name pattern")
 			continue
 		}
 		expectedVersionName := s.ReleaseNameRE.ReplaceAllString(release.Title, s.VersionNameRename)
 		for _, version := range versions {
 			if expectedVersionName == version.Name {
 				rExists = true
+				var outcomes itemOutcomes
 				if diffReleaseAndVersion(release, version) {
+					outcomes.updated = true
 					version, err = s.JiraClient.UpdateVersion(version)
-					if err != nil {
-						return nil, err
-					}
+				}
+				s.Report.add(ReportEntityVersions, outcomes, err)
+				if err != nil {
+					return nil, err
 				}
 				relTuples = append(relTuples, releasesTuple{zhRelease: release, jiraVersion: version})
 			}
 		}
 		if !rExists && release.State == "open" {
 			version, err := s.JiraClient.CreateVersion(expectedVersionName, release.Description, projectID, release.State == "closed", false, release.StartDate, release.DesiredEndDate, release.ClosedAt)
+			s.Report.add(ReportEntityVersions, itemOutcomes{created: true}, err)
 			if err != nil {
 				return nil, err
 			}
@@ -0,0 +1,131 @@
+package pkg
+
+import (
+	"encoding/json"
+	"io"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// Synchronization report entities
+const (
+	ReportEntitySprints  = "sprints"
+	ReportEntityVersions = "versions"
+	ReportEntityEpics    = "epics"
+	ReportEntityIssues   = "issues"
+)
+
+// Report summarizes a synchronization run, it is safe for concurrent use
+type Report struct {
+	Repository string    `json:"repository"`
+	StartedAt  time.Time `json:"started_at"`
+	FinishedAt time.Time `json:"finished_at"`
+	// Error is the error message of a failed synchronization
+	Error string `json:"error,omitempty"`
+	// Entities are items counts indexed by entity
+	Entities map[string]*ReportCounts `json:"entities"`
+
+	lock sync.Mutex
+}
+
+// ReportCounts are counts of items by outcome, an item may be counted in several outcomes except for
+// skipped items that needed no changes and failed items
+type ReportCounts struct {
+	Created      int `json:"created"`
+	Updated      int `json:"updated"`
+	Transitioned int `json:"transitioned"`
+	Commented    int `json:"commented"`
+	Skipped      int `json:"skipped"`
+	Failed       int `json:"failed"`
+}
+
+// itemOutcomes are the changes applied to a single item
+type itemOutcomes struct {
+	created      bool
+	updated      bool
+	transitioned bool
+	commented    bool
+}
+
+// NewReport returns a report of a synchronization starting now
+func NewReport(repository string) *Report {
+	r := &Report{
+		Repository: repository,
+		StartedAt:  time.Now(),
+		Entities:   make(map[string]*ReportCounts),
+	}
+	for _, entity := range []string{ReportEntitySprints, ReportEntityVersions, ReportEntityEpics, ReportEntityIssues} {
+		r.Entities[entity] = new(ReportCounts)
+	}
+	return r
+}
+
+// add counts an item of the given entity, it is counted as failed if err is not nil. A nil report counts nothing.
+func (r *Report) add(entity string, outcomes itemOutcomes, err error) {
+	if r == nil {
+		return
+	}
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	counts := r.Entities[entity]
+	if counts == nil {
+		counts = new(ReportCounts)
+		r.Entities[entity] = counts
+	}
+	switch {
+	case err != nil:
+		counts.Failed++
+	case outcomes == (itemOutcomes{}):
+		counts.Skipped++
+	default:
+		if outcomes.created {
+			counts.Created++
+		}
+		if outcomes.updated {
+			counts.Updated++
+		}
+		if outcomes.transitioned {
+			counts.Transitioned++
+		}
+		if outcomes.commented {
+			counts.Commented++
+		}
+	}
+}
+
+// Finish records the end of the synchronization and its error if any
+func (r *Report) Finish(err error) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	r.FinishedAt = time.Now()
+	if err != nil {
+		r.Error = err.Error()
+	}
+}
+
+// Outcomes returns the numbers of items indexed by entity and outcome
+func (r *Report) Outcomes() map[string]map[string]int {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	outcomes := make(map[string]map[string]int, len(r.Entities))
+	for entity, counts := range r.Entities {
+		outcomes[entity] = map[string]int{
+			"created":      counts.Created,
+			"updated":      counts.Updated,
+			"transitioned": counts.Transitioned,
+			"commented":    counts.Commented,
+			"skipped":      counts.Skipped,
+			"failed":       counts.Failed,
+		}
+	}
+	return outcomes
+}
+
+// WriteJSON writes the report as a single JSON line
+func (r *Report) WriteJSON(w io.Writer) error {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	return errors.Wrap(json.NewEncoder(w).Encode(r), "failed to write synchronization report")
+}
@@ -0,0 +1,48 @@
+package pkg
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestReport(t *testing.T) {
+	r := NewReport("ystia/yorc")
+	r.add(ReportEntityIssues, itemOutcomes{created: true, commented: true}, nil)
+	r.add(ReportEntityIssues, itemOutcomes{updated: true, transitioned: true}, nil)
+	r.add(ReportEntityIssues, itemOutcomes{}, nil)
+	r.add(ReportEntityIssues, itemOutcomes{updated: true}, errors.New("failure"))
+	r.add(ReportEntityEpics, itemOutcomes{}, nil)
+	var nilReport *Report
+	nilReport.add(ReportEntityIssues, itemOutcomes{created: true}, nil)
+
+	expected := ReportCounts{Created: 1, Updated: 1, Transitioned: 1, Commented: 1, Skipped: 1, Failed: 1}
+	if got := *r.Entities[ReportEntityIssues]; got != expected {
+		t.Errorf("issues counts = %+v, expecting %+v", got, expected)
+	}
+	if got := *r.Entities[ReportEntityEpics]; got != (ReportCounts{Skipped: 1}) {
+		t.Errorf("epics counts = %+v, expecting a single skipped epic", got)
+	}
+
+	r.Finish(errors.New("sync failed"))
+	var b bytes.Buffer
+	err := r.WriteJSON(&b)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if strings.Count(b.String(), "\n") != 1 || !strings.Contains(b.String(), `"error":"sync failed"`) ||
+		!strings.Contains(b.String(), `"sprints":{"created":0,"updated":0,"transitioned":0,"commented":0,"skipped":0,"failed":0}`) {
+		t.Errorf("unexpected JSON report %s", b.String())
+	}
+}
+
+func TestReportOutcomes(t *testing.T) {
+	r := NewReport("ystia/yorc")
+	r.add(ReportEntityIssues, itemOutcomes{created: true}, nil)
+	r.add(ReportEntityIssues, itemOutcomes{updated: true}, errors.New("failure"))
+	outcomes := r.Outcomes()
+	if outcomes[ReportEntityIssues]["created"] != 1 || outcomes[ReportEntityIssues]["failed"] != 1 || outcomes[ReportEntityIssues]["updated"] != 0 {
+		t.Errorf("unexpected outcomes %v", outcomes)
+	}
+}
@@ -0,0 +1,124 @@
+package pkg
+
+import (
+	"context"
+
+	"github.com/ystia/zenhub-jira-sync/pkg/clients/zenhub"
+)
+
+// Issue synchronizes a single GitHub issue identified by its number without walking the whole ZenHub board.
+//
+// Epic and releases membership are resolved for this issue only.
+func (s *Sync) Issue(ctx context.Context, number int) error {
+	defer s.reportUnmappedUsers()
+	ghIssue, err := s.GithubClient.GetIssue(ctx, number)
+	if err != nil {
+		return err
+	}
+	if ghIssue.IsPullRequest() {
+		return nil
+	}
+	if ghIssue.GetState() == "closed" {
+		return s.checkClosedIssue(ghIssue)
+	}
+
+	issue, err := s.ZenhubClient.DecorateGithubIssue(ghIssue)
+	if err != nil {
+		return err
+	}
+
+	sprintNamesToIDs := make(map[string]int)
+	sprintList, err := s.JiraClient.ListSprints(ctx)
+	if err != nil {
+		return err
+	}
+	for _, sprint := range sprintList {
+		sprintNamesToIDs[sprint.Name] = sprint.ID
+	}
+
+	epicKey, err := s.getIssueEpicKey(ctx, issue)
+	if err != nil {
+		return err
+	}
+
+	issuesPerReleases, err := s.getIssueReleases(ctx, issue)
+	if err != nil {
+		return err
+	}
+
+	_, err = s.checkIssue(ctx, issue, epicKey, sprintNamesToIDs, issuesPerReleases)
+	return err
+}
+
+// getIssueEpicKey returns the key of the Jira epic of the given issue or an empty string if the issue doesn't belong to an epic
+func (s *Sync) getIssueEpicKey(ctx context.Context, issue *zenhub.Issue) (string, error) {
+	if issue.IsEpic {
+		// Jira doesn't support Epics within epics
+		return "", nil
+	}
+	epics, err := s.ZenhubClient.GetEpics()
+	if err != nil {
+		return "", err
+	}
+	for _, epic := range epics {
+		for _, i := range epic.Issues {
+			if i.IsEpic || i.IssueNumber == nil || *i.IssueNumber != issue.GetNumber() {
+				continue
+			}
+			if i.RepoID != nil && issue.RepoID != nil && *i.RepoID != *issue.RepoID {
+				// Same number in another repository
+				continue
+			}
+			jiraEpic, err := s.getJiraEpic(ctx, epic)
+			if err != nil || jiraEpic == nil {
+				return "", err
+			}
+			return jiraEpic.Key, nil
+		}
+	}
+	return "", nil
+}
+
+// getIssueReleases returns the Jira versions IDs of the ZenHub releases of the given issue, indexed by issue reference
+//
+// Contrary to releases() Jira versions are not created nor updated.
+func (s *Sync) getIssueReleases(ctx context.Context, issue *zenhub.Issue) (map[IssueRef][]string, error) {
+	issuesPerReleases := make(map[IssueRef][]string)
+	issueRef := IssueRef{Number: issue.GetNumber()}
+	versions, err := s.JiraClient.GetProjectVersions()
+	if err != nil {
+		return nil, err
+	}
+	zhReleases, err := s.ZenhubClient.GetReleasesReports()
+	if err != nil {
+		return nil, err
+	}
+	for _, release := range zhReleases {
+		if !s.ReleaseNameRE.MatchString(release.Title) {
+			continue
+		}
+		expectedVersionName := s.ReleaseNameRE.ReplaceAllString(release.Title, s.VersionNameRename)
+		for _, version := range versions {
+			if expectedVersionName != version.Name {
+				continue
+			}
+			issuesIDs, err := s.ZenhubClient.GetIssuesForReleaseReport(release.ID)
+			if err != nil {
+				return nil, err
+			}
+			for _, i := range issuesIDs {
+				if i.IssueNumber == nil || *i.IssueNumber != issue.GetNumber() {
+					continue
+				}
+				ref, err := s.releaseIssueRef(ctx, i)
+				if err != nil {
+					return nil, err
+				}
+				if ref == issueRef {
+					issuesPerReleases[ref] = append(issuesPerReleases[ref], version.ID)
+				}
+			}
+		}
+	}
+	return issuesPerReleases, nil
+}
@@ -0,0 +1,136 @@
+package pkg
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+
+	jiralib "github.com/andygrunwald/go-jira"
+	gh "github.com/google/go-github/v24/github"
+
+	"github.com/ystia/zenhub-jira-sync/pkg/clients/zenhub"
+	"github.com/ystia/zenhub-jira-sync/pkg/markup"
+	"github.com/ystia/zenhub-jira-sync/pkg/state"
+)
+
+// canSkipUnchangedIssues checks if issues that didn't change since the last synchronization could be skipped.
+//
+// This is not the case when features requiring the live Jira state are enabled.
+func (s *Sync) canSkipUnchangedIssues() bool {
+	return s.State != nil && !s.ForceUpdate && !s.RankIssues && !s.JiraCommentsToGithub
+}
+
+// issueFieldsHash returns a hash of all data used to synchronize the given issue fields
+func (s *Sync) issueFieldsHash(issue *zenhub.Issue, epicKey string, issuesPerReleases map[IssueRef][]string) string {
+	var data struct {
+		Title       string           `json:"title"`
+		Body        string           `json:"body"`
+		State       string           `json:"state"`
+		Labels      []string         `json:"labels"`
+		Milestone   string           `json:"milestone"`
+		Estimate    int              `json:"estimate"`
+		Pipeline    string           `json:"pipeline"`
+		EpicKey     string           `json:"epic_key"`
+		Releases    []string         `json:"releases"`
+		Components  []string         `json:"components"`
+		JiraLabels  []string         `json:"jira_labels,omitempty"`
+		Priority    string           `json:"priority,omitempty"`
+		FixVersions []string         `json:"fix_versions,omitempty"`
+		Fields      []LabelRuleField `json:"fields,omitempty"`
+		Author      string           `json:"author"`
+		Assignees   []string         `json:"assignees"`
+	}
+	data.Title = issue.GetTitle()
+	// Hash the converted form so that converter changes are applied to unchanged issues
+	data.Body = markup.ToJiraWiki(issue.GetBody())
+	data.State = issue.GetState()
+	data.Labels = getZHIssueLabels(issue)
+	data.Milestone = issue.GetMilestone().GetTitle()
+	if issue.Estimate != nil {
+		data.Estimate = issue.Estimate.Value
+	}
+	if issue.Pipeline != nil {
+		data.Pipeline = issue.Pipeline.Name
+	}
+	data.EpicKey = epicKey
+	data.Releases = issuesPerReleases[IssueRef{Number: issue.GetNumber()}]
+	rules := applyLabelRules(s.LabelRules, data.Labels)
+	data.Components = s.issueComponents(rules)
+	data.Priority = rules.priority
+	data.FixVersions = rules.fixVersions
+	data.Fields = rules.fields
+	if s.NativeLabels != nil {
+		data.JiraLabels = s.NativeLabels.jiraLabels(data.Labels)
+	}
+	data.Author = issue.GetUser().GetLogin()
+	for _, assignee := range issue.Assignees {
+		data.Assignees = append(data.Assignees, assignee.GetLogin())
+	}
+	return hashJSON(data)
+}
+
+// commentsHash returns a hash of the given GitHub comments
+func commentsHash(comments []*gh.IssueComment) string {
+	type commentData struct {
+		ID   int64  `json:"id"`
+		Body string `json:"body"`
+	}
+	data := make([]commentData, len(comments))
+	for i, c := range comments {
+		data[i] = commentData{ID: c.GetID(), Body: markup.ToJiraWiki(c.GetBody())}
+	}
+	return hashJSON(data)
+}
+
+func hashJSON(v interface{}) string {
+	// Marshaling those structures could not fail
+	b, _ := json.Marshal(v)
+	h := sha256.Sum256(b)
+	return hex.EncodeToString(h[:])
+}
+
+// getUnchangedIssue returns the Jira issue associated to the given issue if it didn't change since the last synchronization.
+//
+// GitHub comments are fetched if needed to check for changes and returned to be reused.
+func (s *Sync) getUnchangedIssue(ctx context.Context, issue *zenhub.Issue, fieldsHash string) (*jiralib.Issue, []*gh.IssueComment, error) {
+	if !s.canSkipUnchangedIssues() {
+		return nil, nil, nil
+	}
+	st, ok := s.State.GetIssue(issue.GetID())
+	if !ok || st.FieldsHash != fieldsHash {
+		return nil, nil, nil
+	}
+	if st.UpdatedAt.Equal(issue.GetUpdatedAt()) {
+		return &jiralib.Issue{Key: st.JiraKey}, nil, nil
+	}
+	// Fields didn't change, only comments may have changed
+	ghComments, err := s.getGithubComments(ctx, issue.Issue)
+	if err != nil {
+		return nil, nil, err
+	}
+	if commentsHash(ghComments) != st.CommentsHash {
+		return nil, ghComments, nil
+	}
+	st.UpdatedAt = issue.GetUpdatedAt()
+	s.State.SetIssue(issue.GetID(), st)
+	return &jiralib.Issue{Key: st.JiraKey}, ghComments, nil
+}
+
+// recordIssueState stores the state of a successfully synchronized issue
+func (s *Sync) recordIssueState(issue *zenhub.Issue, jiraIssue *jiralib.Issue, fieldsHash string, ghComments []*gh.IssueComment) {
+	if s.State == nil {
+		return
+	}
+	st := state.IssueState{
+		JiraKey:      jiraIssue.Key,
+		UpdatedAt:    issue.GetUpdatedAt(),
+		FieldsHash:   fieldsHash,
+		CommentsHash: commentsHash(ghComments),
+	}
+	if s.NativeLabels != nil {
+		st.JiraLabels = s.NativeLabels.jiraLabels(getZHIssueLabels(issue))
+	}
+	st.RuleComponents = applyLabelRules(s.LabelRules, getZHIssueLabels(issue)).components
+	s.State.SetIssue(issue.GetID(), st)
+}
@@ -0,0 +1,109 @@
+package state
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// IssueState is the synchronization state of a single GitHub issue
+type IssueState struct {
+	// JiraKey is the key of the Jira issue associated to the GitHub issue
+	JiraKey string `json:"jira_key"`
+	// UpdatedAt is the GitHub issue update date at the time of the last synchronization
+	UpdatedAt time.Time `json:"updated_at"`
+	// FieldsHash is a hash of the GitHub and ZenHub data pushed to Jira during the last synchronization
+	FieldsHash string `json:"fields_hash,omitempty"`
+	// CommentsHash is a hash of the GitHub comments pushed to Jira during the last synchronization
+	CommentsHash string `json:"comments_hash,omitempty"`
+	// Closed is true if the Jira issue was closed during the last synchronization
+	Closed bool `json:"closed,omitempty"`
+	// JiraLabels are the Jira labels written from GitHub labels during the last synchronization
+	JiraLabels []string `json:"jira_labels,omitempty"`
+	// RuleComponents are the Jira components added by label rules during the last synchronization
+	RuleComponents []string `json:"rule_components,omitempty"`
+}
+
+// Store is a file based store of the synchronization state of GitHub issues, keyed by GitHub issue ID.
+//
+// Store is safe for concurrent use.
+type Store struct {
+	path   string
+	lock   sync.Mutex
+	issues map[int64]IssueState
+	// saveLock serializes writes of the temporary file
+	saveLock sync.Mutex
+}
+
+type storeContent struct {
+	Issues map[int64]IssueState `json:"issues"`
+}
+
+// Open loads the store with the given name from the given directory.
+//
+// The directory is created if needed and an empty store is returned if it doesn't exist yet.
+func Open(stateDir, name string) (*Store, error) {
+	err := os.MkdirAll(stateDir, 0700)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to create state directory %q", stateDir)
+	}
+	s := &Store{
+		path:   filepath.Join(stateDir, name+".json"),
+		issues: make(map[int64]IssueState),
+	}
+	b, err := ioutil.ReadFile(s.path)
+	if os.IsNotExist(err) {
+		return s, nil
+	}
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to read state file %q", s.path)
+	}
+	content := storeContent{Issues: s.issues}
+	err = json.Unmarshal(b, &content)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to decode state file %q", s.path)
+	}
+	if content.Issues != nil {
+		s.issues = content.Issues
+	}
+	return s, nil
+}
+
+// GetIssue returns the state of the given GitHub issue, the returned boolean is false if there is no known state for this issue
+func (s *Store) GetIssue(ghIssueID int64) (IssueState, bool) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	st, ok := s.issues[ghIssueID]
+	return st, ok
+}
+
+// SetIssue sets the state of the given GitHub issue
+func (s *Store) SetIssue(ghIssueID int64, st IssueState) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.issues[ghIssueID] = st
+}
+
+// Save persists the store on disk
+func (s *Store) Save() error {
+	s.saveLock.Lock()
+	defer s.saveLock.Unlock()
+	s.lock.Lock()
+	b, err := json.Marshal(storeContent{Issues: s.issues})
+	s.lock.Unlock()
+	if err != nil {
+		return errors.Wrapf(err, "failed to encode state file %q", s.path)
+	}
+	// Write to a temporary file first to not corrupt the existing state on failure
+	tmpFile := s.path + ".tmp"
+	err = ioutil.WriteFile(tmpFile, b, 0600)
+	if err != nil {
+		return errors.Wrapf(err, "failed to write state file %q", tmpFile)
+	}
+	return errors.Wrapf(os.Rename(tmpFile, s.path), "failed to write state file %q", s.path)
+}
@@ -0,0 +1,128 @@
+package state
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+)
+
+func newTestDir(t *testing.T) (string, func()) {
+	dir, err := ioutil.TempDir("", "state")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	return dir, func() { os.RemoveAll(dir) }
+}
+
+func TestStoreRoundTrip(t *testing.T) {
+	dir, cleanup := newTestDir(t)
+	defer cleanup()
+
+	s, err := Open(filepath.Join(dir, "sub"), "repo")
+	if err != nil {
+		t.Fatalf("unexpected error opening a missing store: %v", err)
+	}
+	if _, ok := s.GetIssue(1); ok {
+		t.Fatal("expecting a missing store to be empty")
+	}
+	expected := IssueState{
+		JiraKey:        "PRJ-1",
+		UpdatedAt:      time.Date(2020, 5, 4, 10, 0, 0, 0, time.UTC),
+		FieldsHash:     "fields",
+		CommentsHash:   "comments",
+		Closed:         true,
+		JiraLabels:     []string{"gh-bug"},
+		RuleComponents: []string{"backend"},
+	}
+	s.SetIssue(1, expected)
+	err = s.Save()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, err = os.Stat(s.path + ".tmp"); !os.IsNotExist(err) {
+		t.Errorf("expecting the temporary file to be renamed, got %v", err)
+	}
+
+	s, err = Open(filepath.Join(dir, "sub"), "repo")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	st, ok := s.GetIssue(1)
+	if !ok || st.JiraKey != expected.JiraKey || !st.UpdatedAt.Equal(expected.UpdatedAt) || st.FieldsHash != expected.FieldsHash ||
+		st.CommentsHash != expected.CommentsHash || !st.Closed || len(st.JiraLabels) != 1 || len(st.RuleComponents) != 1 {
+		t.Errorf("GetIssue() = %+v, %v, expecting %+v", st, ok, expected)
+	}
+}
+
+func TestStoreCorruptFile(t *testing.T) {
+	dir, cleanup := newTestDir(t)
+	defer cleanup()
+	err := ioutil.WriteFile(filepath.Join(dir, "repo.json"), []byte("{not json"), 0600)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	_, err = Open(dir, "repo")
+	if err == nil {
+		t.Error("expecting an error opening a corrupt store")
+	}
+}
+
+func TestStoreSaveFailureKeepsState(t *testing.T) {
+	dir, cleanup := newTestDir(t)
+	defer cleanup()
+	s, err := Open(dir, "repo")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	s.SetIssue(1, IssueState{JiraKey: "PRJ-1"})
+	err = s.Save()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	// The temporary file could not be written if a directory exists at its path
+	err = os.Mkdir(s.path+".tmp", 0700)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	s.SetIssue(1, IssueState{JiraKey: "PRJ-2"})
+	if err = s.Save(); err == nil {
+		t.Fatal("expecting an error saving the store")
+	}
+	s, err = Open(dir, "repo")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if st, _ := s.GetIssue(1); st.JiraKey != "PRJ-1" {
+		t.Errorf("expecting the previous state to be kept, got %+v", st)
+	}
+}
+
+func TestStoreConcurrentAccess(t *testing.T) {
+	dir, cleanup := newTestDir(t)
+	defer cleanup()
+	s, err := Open(dir, "repo")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var wg sync.WaitGroup
+	for i := int64(0); i < 10; i++ {
+		wg.Add(1)
+		go func(id int64) {
+			defer wg.Done()
+			s.SetIssue(id, IssueState{JiraKey: "PRJ"})
+			s.GetIssue(id)
+			if err := s.Save(); err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+		}(i)
+	}
+	wg.Wait()
+	for i := int64(0); i < 10; i++ {
+		if _, ok := s.GetIssue(i); !ok {
+			t.Errorf("missing state of issue %d", i)
+		}
+	}
+}
@@ -0,0 +1,83 @@
+package pkg
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"testing"
+	"time"
+
+	gh "github.com/google/go-github/v24/github"
+
+	"github.com/ystia/zenhub-jira-sync/pkg/clients/zenhub"
+	"github.com/ystia/zenhub-jira-sync/pkg/state"
+)
+
+func TestCanSkipUnchangedIssues(t *testing.T) {
+	store := &state.Store{}
+	tests := []struct {
+		name     string
+		sync     *Sync
+		expected bool
+	}{
+		{"NoState", &Sync{}, false},
+		{"State", &Sync{State: store}, true},
+		{"ForceUpdate", &Sync{State: store, ForceUpdate: true}, false},
+		{"RankIssues", &Sync{State: store, RankIssues: true}, false},
+		{"JiraCommentsToGithub", &Sync{State: store, JiraCommentsToGithub: true}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.sync.canSkipUnchangedIssues(); got != tt.expected {
+				t.Errorf("canSkipUnchangedIssues() = %v, expecting %v", got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestGetUnchangedIssue(t *testing.T) {
+	dir, err := ioutil.TempDir("", "state")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer os.RemoveAll(dir)
+	store, err := state.Open(dir, "repo")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	updatedAt := time.Date(2020, 5, 4, 10, 0, 0, 0, time.UTC)
+	store.SetIssue(1, state.IssueState{JiraKey: "PRJ-1", UpdatedAt: updatedAt, FieldsHash: "hash", CommentsHash: commentsHash(nil)})
+	s := &Sync{State: store}
+
+	newIssue := func(updatedAt time.Time) *zenhub.Issue {
+		id := int64(1)
+		return &zenhub.Issue{Issue: &gh.Issue{ID: &id, UpdatedAt: &updatedAt}}
+	}
+	tests := []struct {
+		name       string
+		issue      *zenhub.Issue
+		fieldsHash string
+		expected   bool
+	}{
+		{"Unchanged", newIssue(updatedAt), "hash", true},
+		{"FieldsChanged", newIssue(updatedAt), "other", false},
+		{"UpdatedWithoutChanges", newIssue(updatedAt.Add(time.Hour)), "hash", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			jiraIssue, _, err := s.getUnchangedIssue(context.Background(), tt.issue, tt.fieldsHash)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if (jiraIssue != nil) != tt.expected {
+				t.Errorf("getUnchangedIssue() = %v, expecting the issue to be skipped: %v", jiraIssue, tt.expected)
+			}
+			if jiraIssue != nil && jiraIssue.Key != "PRJ-1" {
+				t.Errorf("getUnchangedIssue() key = %q, expecting %q", jiraIssue.Key, "PRJ-1")
+			}
+		})
+	}
+	if st, _ := store.GetIssue(1); !st.UpdatedAt.Equal(updatedAt.Add(time.Hour)) {
+		t.Errorf("expecting the update date of the issue to be recorded, got %v", st.UpdatedAt)
+	}
+}
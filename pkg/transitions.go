@@ -0,0 +1,197 @@
+package pkg
+
+import (
+	"strings"
+
+	jiralib "github.com/andygrunwald/go-jira"
+)
+
+// maxTransitionsWalk is the maximum number of transitions applied while walking the workflow to reach a status
+const maxTransitionsWalk = 10
+
+// statusCategoriesRanks orders Jira status categories from the beginning to the end of workflows
+var statusCategoriesRanks = map[string]int{
+	jiralib.StatusCategoryToDo:       0,
+	jiralib.StatusCategoryInProgress: 1,
+	jiralib.StatusCategoryComplete:   2,
+}
+
+// Default transitions walk the workflow up to a status of the done category when closing issues and back
+// to a status of the to do category when reopening them
+var (
+	defaultClosedIssuesTransition   = &StatusTransition{StatusCategory: jiralib.StatusCategoryComplete}
+	defaultReopenedIssuesTransition = &StatusTransition{StatusCategory: jiralib.StatusCategoryToDo}
+)
+
+// StatusTransition defines how Jira issues are moved to target statuses
+type StatusTransition struct {
+	// StatusCategory is the key of the status category of target statuses (new, indeterminate or done),
+	// it is used when Statuses is empty
+	StatusCategory string
+	// Statuses are names of target statuses
+	Statuses []string
+	// Transitions is an optional ordered list of transitions names to apply, transitions that are not available
+	// from the current issue status are skipped. If empty, available transitions are walked until a target status
+	// is reached, preferring transitions leading directly to a target status.
+	Transitions []string
+	// Fields are values of fields of transitions screens, such as the resolution, indexed by field ID
+	Fields map[string]string
+}
+
+// isTarget returns true if the given status is a target status of the transition
+func (t *StatusTransition) isTarget(status *jiralib.Status) bool {
+	if status == nil {
+		return false
+	}
+	if len(t.Statuses) == 0 {
+		return status.StatusCategory.Key == t.StatusCategory
+	}
+	for _, name := range t.Statuses {
+		if strings.EqualFold(name, status.Name) {
+			return true
+		}
+	}
+	return false
+}
+
+// fieldsFor returns the configured fields values that could be set by the given transition
+func (t *StatusTransition) fieldsFor(transition jiralib.Transition) map[string]interface{} {
+	var fields map[string]interface{}
+	for id, value := range t.Fields {
+		if _, ok := transition.Fields[id]; !ok {
+			// Setting a field which is not on the transition screen fails
+			continue
+		}
+		if fields == nil {
+			fields = make(map[string]interface{})
+		}
+		switch id {
+		case "resolution", "priority":
+			fields[id] = map[string]string{"name": value}
+		default:
+			fields[id] = value
+		}
+	}
+	return fields
+}
+
+// nextTransition returns the available transition to apply in order to get closer to a target status,
+// statuses already visited are not considered. It returns nil if there is no such transition.
+func (t *StatusTransition) nextTransition(transitions []jiralib.Transition, visited map[string]bool) *jiralib.Transition {
+	var next *jiralib.Transition
+	targetRank, rankTarget := statusCategoriesRanks[t.StatusCategory]
+	bestDistance := len(statusCategoriesRanks)
+	for i := range transitions {
+		to := &transitions[i].To
+		if t.isTarget(to) {
+			return &transitions[i]
+		}
+		if visited[strings.ToLower(to.Name)] {
+			continue
+		}
+		// Statuses of unknown categories come last
+		distance := len(statusCategoriesRanks) - 1
+		if rank, ok := statusCategoriesRanks[to.StatusCategory.Key]; ok && rankTarget {
+			distance = targetRank - rank
+			if distance < 0 {
+				distance = -distance
+			}
+		}
+		if distance < bestDistance {
+			next = &transitions[i]
+			bestDistance = distance
+		}
+	}
+	return next
+}
+
+// closedIssuesTransition returns the transition used to close Jira issues
+func (s *Sync) closedIssuesTransition() *StatusTransition {
+	if s.ClosedIssuesTransition != nil {
+		return s.ClosedIssuesTransition
+	}
+	return defaultClosedIssuesTransition
+}
+
+// reopenedIssuesTransition returns the transition used to reopen Jira issues
+func (s *Sync) reopenedIssuesTransition() *StatusTransition {
+	if s.ReopenedIssuesTransition != nil {
+		return s.ReopenedIssuesTransition
+	}
+	return defaultReopenedIssuesTransition
+}
+
+// applyStatusTransition applies transitions on the given issue until it reaches a target status of t.
+//
+// It returns the reached target status or nil if none was reached.
+func (s *Sync) applyStatusTransition(issueKey string, status *jiralib.Status, t *StatusTransition) (*jiralib.Status, error) {
+	if t.isTarget(status) {
+		return status, nil
+	}
+	logger := s.logger().WithField("jira_key", issueKey)
+	if len(t.Transitions) > 0 {
+		for _, name := range t.Transitions {
+			transitions, err := s.JiraClient.GetIssueTransitions(issueKey)
+			if err != nil {
+				return nil, err
+			}
+			for _, tr := range transitions {
+				if !strings.EqualFold(tr.Name, name) {
+					continue
+				}
+				logger.Infof("Applying transition %q", tr.Name)
+				err = s.JiraClient.TransitionIssueWithFields(issueKey, tr.Name, t.fieldsFor(tr))
+				if err != nil {
+					return nil, err
+				}
+				if t.isTarget(&tr.To) {
+					return &tr.To, nil
+				}
+				break
+			}
+		}
+		logger.Warnf("Transitions %q did not move issue to a target status", strings.Join(t.Transitions, ", "))
+		return nil, nil
+	}
+
+	visited := make(map[string]bool)
+	if status != nil {
+		visited[strings.ToLower(status.Name)] = true
+	}
+	var previous string
+	for i := 0; i < maxTransitionsWalk; i++ {
+		transitions, err := s.JiraClient.GetIssueTransitions(issueKey)
+		if err != nil {
+			return nil, err
+		}
+		current := transitionsIDs(transitions)
+		if i > 0 && current == previous {
+			// The issue status did not change, as in dry-run mode
+			return nil, nil
+		}
+		previous = current
+		tr := t.nextTransition(transitions, visited)
+		if tr == nil {
+			break
+		}
+		logger.Infof("Applying transition %q", tr.Name)
+		err = s.JiraClient.TransitionIssueWithFields(issueKey, tr.Name, t.fieldsFor(*tr))
+		if err != nil {
+			return nil, err
+		}
+		if t.isTarget(&tr.To) {
+			return &tr.To, nil
+		}
+		visited[strings.ToLower(tr.To.Name)] = true
+	}
+	logger.Warnf("No transitions found to move issue to a target status")
+	return nil, nil
+}
+
+func transitionsIDs(transitions []jiralib.Transition) string {
+	ids := make([]string, len(transitions))
+	for i, t := range transitions {
+		ids[i] = t.ID
+	}
+	return strings.Join(ids, ",")
+}
@@ -0,0 +1,53 @@
+package pkg
+
+import (
+	"testing"
+
+	jiralib "github.com/andygrunwald/go-jira"
+)
+
+func TestStatusTransitionNextTransition(t *testing.T) {
+	status := func(name, category string) jiralib.Status {
+		return jiralib.Status{Name: name, StatusCategory: jiralib.StatusCategory{Key: category}}
+	}
+	transitions := []jiralib.Transition{
+		{ID: "1", Name: "Back to backlog", To: status("Backlog", "new")},
+		{ID: "2", Name: "Start", To: status("In Progress", "indeterminate")},
+		{ID: "3", Name: "Resolve", To: status("Resolved", "done")},
+	}
+	tests := []struct {
+		name        string
+		transition  *StatusTransition
+		transitions []jiralib.Transition
+		visited     map[string]bool
+		want        string
+	}{
+		{"DirectCategory", &StatusTransition{StatusCategory: "done"}, transitions, nil, "Resolve"},
+		{"DirectStatus", &StatusTransition{StatusCategory: "done", Statuses: []string{"backlog"}}, transitions, nil, "Back to backlog"},
+		{"ClosestCategory", &StatusTransition{StatusCategory: "done"}, transitions[:2], nil, "Start"},
+		{"SkipVisited", &StatusTransition{StatusCategory: "done"}, transitions[:2], map[string]bool{"in progress": true}, "Back to backlog"},
+		{"None", &StatusTransition{StatusCategory: "done"}, transitions[:1], map[string]bool{"backlog": true}, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got string
+			if next := tt.transition.nextTransition(tt.transitions, tt.visited); next != nil {
+				got = next.Name
+			}
+			if got != tt.want {
+				t.Errorf("nextTransition() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestStatusTransitionFieldsFor(t *testing.T) {
+	st := &StatusTransition{Fields: map[string]string{"resolution": "Fixed", "customfield_10001": "value"}}
+	fields := st.fieldsFor(jiralib.Transition{Fields: map[string]jiralib.TransitionField{"resolution": {Required: true}}})
+	if len(fields) != 1 {
+		t.Fatalf("fieldsFor() = %v, expecting only the resolution", fields)
+	}
+	if v, ok := fields["resolution"].(map[string]string); !ok || v["name"] != "Fixed" {
+		t.Errorf("fieldsFor() resolution = %v, expecting it to be set by name", fields["resolution"])
+	}
+}
@@ -2,11 +2,16 @@ package pkg
 
 import (
 	"context"
+	"net/http"
 	"regexp"
+	"sync"
+
+	"github.com/sirupsen/logrus"
 
 	"github.com/ystia/zenhub-jira-sync/pkg/clients/github"
 	"github.com/ystia/zenhub-jira-sync/pkg/clients/jira"
 	"github.com/ystia/zenhub-jira-sync/pkg/clients/zenhub"
+	"github.com/ystia/zenhub-jira-sync/pkg/state"
 )
 
 // Sync is our synchronization tool
@@ -22,10 +27,85 @@ type Sync struct {
 		IssueType string
 	}
 	DefaultJiraComponents []string
+	PipelinesToStatuses   []PipelineToStatus
+	// ClosedIssuesTransition defines how Jira issues are closed when their GitHub issue is closed,
+	// if nil issues are moved to a status of the done category
+	ClosedIssuesTransition *StatusTransition
+	// ReopenedIssuesTransition defines how closed Jira issues are reopened when their GitHub issue is reopened,
+	// if nil issues are moved to a status of the to do category
+	ReopenedIssuesTransition *StatusTransition
+	// JiraCommentsToGithub enables the copy of Jira comments to GitHub issues
+	JiraCommentsToGithub bool
+	// RankIssues enables the ranking of Jira issues based on their position in ZenHub pipelines
+	RankIssues bool
+	// State allows to skip issues that didn't change since the last synchronization, it may be nil
+	State *state.Store
+	// ForceUpdate disables skipping issues that didn't change since the last synchronization
+	ForceUpdate bool
+	// Concurrency is the maximum number of issues synchronized concurrently, defaults to 1
+	Concurrency int
+	// AttachmentsFetcher downloads images and files attached to GitHub issues and comments in order to mirror
+	// them as Jira attachments. Mirroring is disabled if nil.
+	AttachmentsFetcher HTTPFetcher
+
+	// SynchronizedRepositories are the full names of all synchronized GitHub repositories, issues of those
+	// repositories are linked to epics of other synchronized repositories
+	SynchronizedRepositories []string
+	// UserMapping enables the synchronization of issues reporters and assignees, it may be nil
+	UserMapping *UserMapping
+	// OrphanIssues enables handling Jira issues whose GitHub issue was transferred, deleted or converted to
+	// a discussion, it may be nil
+	OrphanIssues *OrphanIssues
+	// NativeLabels enables synchronizing GitHub labels as Jira labels, it may be nil
+	NativeLabels *NativeLabels
+	// LabelRules set the priority, components, fix versions and fields of Jira issues based on their GitHub labels
+	LabelRules []LabelRule
+	// Logger is used to log synchronization events, the default logger is used if nil
+	Logger *logrus.Entry
+	// Report counts items changed by the synchronization, it may be nil
+	Report *Report
+
+	attachmentsLock sync.Mutex
+	// attachments caches names of mirrored attachments indexed by Jira issue key and URL
+	attachments map[string]string
+
+	repositoriesLock         sync.Mutex
+	repositoryID             int64
+	synchronizedRepositories map[int64]bool
+
+	usersLock sync.Mutex
+	// jiraUsers caches Jira users identifiers looked up by email indexed by lower-cased GitHub login
+	jiraUsers     map[string]string
+	unmappedUsers map[string]struct{}
+
+	versionsLock sync.Mutex
+	// versionsIDs caches IDs of Jira versions set by label rules indexed by name
+	versionsIDs map[string]string
+}
+
+// HTTPFetcher sends HTTP requests, it is implemented by *http.Client
+type HTTPFetcher interface {
+	Do(req *http.Request) (*http.Response, error)
+}
+
+// PipelineToStatus maps a ZenHub pipeline to Jira statuses, issues of the pipeline are moved to a target status
+// of Transition
+type PipelineToStatus struct {
+	Pipeline   string
+	Transition StatusTransition
+}
+
+// logger returns the logger of the synchronization
+func (s *Sync) logger() *logrus.Entry {
+	if s.Logger != nil {
+		return s.Logger
+	}
+	return logrus.NewEntry(logrus.StandardLogger())
 }
 
 // All synchronize every thing
 func (s *Sync) All(ctx context.Context) error {
+	defer s.reportUnmappedUsers()
 
 	err := s.milestones(ctx)
 	if err != nil {
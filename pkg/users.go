@@ -0,0 +1,121 @@
+package pkg
+
+import (
+	"context"
+	"sort"
+	"strings"
+
+	"github.com/ystia/zenhub-jira-sync/pkg/clients/zenhub"
+)
+
+// UserMapping maps GitHub users to Jira users
+type UserMapping struct {
+	// Users are Jira users identifiers, account IDs or usernames for Jira Server, indexed by lower-cased GitHub login
+	Users map[string]string
+	// LookupByEmail enables looking up Jira users using the public email address of GitHub users
+	LookupByEmail bool
+	// Fallback is the identifier of the Jira user used for GitHub users that could not be mapped, it may be empty
+	Fallback string
+	// Unassign enables clearing the assignee of Jira issues whose GitHub issue has no assignee, by default
+	// assignees set in Jira are kept
+	Unassign bool
+}
+
+// issueUsers are identifiers of the Jira users mapped to the GitHub users of an issue
+type issueUsers struct {
+	reporter string
+	assignee string
+	// unassigned is true if the GitHub issue has no assignee, assignee is then empty
+	unassigned bool
+}
+
+// getIssueUsers returns the Jira users mapped to the author and assignees of the given issue.
+//
+// As Jira issues have a single assignee, the first mapped GitHub assignee is used.
+// It returns nil if users mapping is disabled.
+func (s *Sync) getIssueUsers(ctx context.Context, issue *zenhub.Issue) (*issueUsers, error) {
+	if s.UserMapping == nil {
+		return nil, nil
+	}
+	users := &issueUsers{unassigned: len(issue.Assignees) == 0}
+	var err error
+	users.reporter, err = s.getJiraUserID(ctx, issue.GetUser().GetLogin())
+	if err != nil {
+		return nil, err
+	}
+	if users.reporter == "" {
+		users.reporter = s.UserMapping.Fallback
+	}
+	for _, assignee := range issue.Assignees {
+		users.assignee, err = s.getJiraUserID(ctx, assignee.GetLogin())
+		if err != nil {
+			return nil, err
+		}
+		if users.assignee != "" {
+			break
+		}
+	}
+	if users.assignee == "" && len(issue.Assignees) > 0 {
+		users.assignee = s.UserMapping.Fallback
+	}
+	return users, nil
+}
+
+// getJiraUserID returns the identifier of the Jira user mapped to the given GitHub login or an empty string if
+// it could not be mapped
+func (s *Sync) getJiraUserID(ctx context.Context, login string) (string, error) {
+	if login == "" {
+		return "", nil
+	}
+	login = strings.ToLower(login)
+	if id, ok := s.UserMapping.Users[login]; ok {
+		return id, nil
+	}
+
+	s.usersLock.Lock()
+	id, ok := s.jiraUsers[login]
+	s.usersLock.Unlock()
+	if !ok && s.UserMapping.LookupByEmail {
+		ghUser, err := s.GithubClient.GetUser(ctx, login)
+		if err != nil {
+			return "", err
+		}
+		if ghUser.GetEmail() != "" {
+			jiraUser, err := s.JiraClient.FindUserByEmail(ghUser.GetEmail())
+			if err != nil {
+				return "", err
+			}
+			id = s.JiraClient.GetUserID(jiraUser)
+		}
+	}
+
+	s.usersLock.Lock()
+	defer s.usersLock.Unlock()
+	if s.jiraUsers == nil {
+		s.jiraUsers = make(map[string]string)
+	}
+	s.jiraUsers[login] = id
+	if id == "" {
+		if s.unmappedUsers == nil {
+			s.unmappedUsers = make(map[string]struct{})
+		}
+		s.unmappedUsers[login] = struct{}{}
+	}
+	return id, nil
+}
+
+// reportUnmappedUsers logs GitHub users that could not be mapped to Jira users since the last report
+func (s *Sync) reportUnmappedUsers() {
+	s.usersLock.Lock()
+	defer s.usersLock.Unlock()
+	if len(s.unmappedUsers) == 0 {
+		return
+	}
+	logins := make([]string, 0, len(s.unmappedUsers))
+	for login := range s.unmappedUsers {
+		logins = append(logins, login)
+	}
+	sort.Strings(logins)
+	s.logger().Warnf("GitHub users not mapped to Jira users: %s", strings.Join(logins, ", "))
+	s.unmappedUsers = nil
+}
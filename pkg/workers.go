@@ -0,0 +1,99 @@
+package pkg
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// IssueRef identifies a GitHub issue, RepoID is zero for issues of the synchronized repository
+type IssueRef struct {
+	RepoID int64
+	Number int
+}
+
+func (r IssueRef) String() string {
+	if r.RepoID == 0 {
+		return fmt.Sprintf("#%d", r.Number)
+	}
+	return fmt.Sprintf("repository %d#%d", r.RepoID, r.Number)
+}
+
+// IssuesErrors aggregates errors that occurred while synchronizing issues, indexed by GitHub issue
+type IssuesErrors map[IssueRef]error
+
+func (e IssuesErrors) Error() string {
+	refs := make([]IssueRef, 0, len(e))
+	for ref := range e {
+		refs = append(refs, ref)
+	}
+	sort.Slice(refs, func(i, j int) bool {
+		if refs[i].RepoID != refs[j].RepoID {
+			return refs[i].RepoID < refs[j].RepoID
+		}
+		return refs[i].Number < refs[j].Number
+	})
+	msgs := make([]string, len(refs))
+	for i, ref := range refs {
+		msgs[i] = fmt.Sprintf("%s: %v", ref, e[ref])
+	}
+	return fmt.Sprintf("failed to synchronize %d issue(s): %s", len(e), strings.Join(msgs, "; "))
+}
+
+// issuesErrorsCollector collects errors of concurrent issues synchronizations
+type issuesErrorsCollector struct {
+	lock   sync.Mutex
+	errors IssuesErrors
+}
+
+func (c *issuesErrorsCollector) add(ref IssueRef, err error) {
+	if err == nil {
+		return
+	}
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	if c.errors == nil {
+		c.errors = make(IssuesErrors)
+	}
+	c.errors[ref] = err
+}
+
+// err returns collected errors as an IssuesErrors or nil if there is no error
+func (c *issuesErrorsCollector) err() error {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	if len(c.errors) == 0 {
+		return nil
+	}
+	return c.errors
+}
+
+// forEach calls f for each index in [0, n) using a pool of at most s.Concurrency workers.
+//
+// It returns when all calls are done.
+func (s *Sync) forEach(n int, f func(i int)) {
+	workers := s.Concurrency
+	if workers < 1 {
+		workers = 1
+	}
+	if workers > n {
+		workers = n
+	}
+	indexes := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range indexes {
+				f(i)
+			}
+		}()
+	}
+	for i := 0; i < n; i++ {
+		indexes <- i
+	}
+	close(indexes)
+	wg.Wait()
+}
@@ -0,0 +1,38 @@
+package pkg
+
+import (
+	"errors"
+	"sync/atomic"
+	"testing"
+)
+
+func TestSyncForEach(t *testing.T) {
+	for _, concurrency := range []int{0, 1, 4, 100} {
+		s := &Sync{Concurrency: concurrency}
+		visits := make([]int32, 10)
+		s.forEach(len(visits), func(i int) {
+			atomic.AddInt32(&visits[i], 1)
+		})
+		for i, v := range visits {
+			if v != 1 {
+				t.Errorf("concurrency %d: index %d visited %d times, expecting 1", concurrency, i, v)
+			}
+		}
+	}
+}
+
+func TestIssuesErrorsCollector(t *testing.T) {
+	errs := new(issuesErrorsCollector)
+	errs.add(IssueRef{Number: 1}, nil)
+	if errs.err() != nil {
+		t.Fatalf("expecting no error, got %v", errs.err())
+	}
+	errs.add(IssueRef{Number: 12}, errors.New("second"))
+	errs.add(IssueRef{Number: 3}, errors.New("first"))
+	// Issues of other repositories do not override issues of this repository having the same number
+	errs.add(IssueRef{RepoID: 42, Number: 3}, errors.New("other"))
+	expected := "failed to synchronize 3 issue(s): #3: first; #12: second; repository 42#3: other"
+	if errs.err() == nil || errs.err().Error() != expected {
+		t.Errorf("unexpected error %v, expecting %q", errs.err(), expected)
+	}
+}